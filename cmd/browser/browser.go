@@ -0,0 +1,51 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+type API interface {
+	Open(url string) error
+}
+
+// Browser hands URLs to the browser of the system, the one set in $BROWSER if any.
+type Browser struct {
+	command []string
+}
+
+func NewBrowser() *Browser {
+	// $BROWSER may list several browsers, the first one is enough
+	if browser, _, _ := strings.Cut(os.Getenv("BROWSER"), ":"); strings.TrimSpace(browser) != "" {
+		return &Browser{command: strings.Fields(browser)}
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return &Browser{command: []string{"open"}}
+	case "windows":
+		return &Browser{command: []string{"rundll32", "url.dll,FileProtocolHandler"}}
+	default:
+		return &Browser{command: []string{"xdg-open"}}
+	}
+}
+
+// Open starts the browser on url without waiting for it, its output is discarded
+// so that it does not garble the terminal.
+func (b *Browser) Open(url string) error {
+	args := append(b.command[1:len(b.command):len(b.command)], url)
+	cmd := exec.Command(b.command[0], args...) //nolint:gosec // The browser is the user's choice
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error opening browser: %w", err)
+	}
+
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
+}
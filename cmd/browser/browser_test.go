@@ -0,0 +1,48 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		browser string
+	}{
+		{"browser", "touch"},
+		{"first of a list of browsers", "touch:firefox"},
+		{"browser with arguments", "touch -m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// touch stands in for the browser, the URL it is given is a file it creates
+			t.Setenv("BROWSER", tt.browser)
+			url := filepath.Join(t.TempDir(), "opened")
+
+			if err := NewBrowser().Open(url); err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for _, err := os.Stat(url); err != nil; _, err = os.Stat(url) {
+				if time.Now().After(deadline) {
+					t.Fatalf("Open() did not start %s on %s", tt.browser, url)
+				}
+
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestOpenMissing(t *testing.T) {
+	t.Setenv("BROWSER", "chamot-no-such-browser")
+
+	if err := NewBrowser().Open("https://news.ycombinator.com"); err == nil {
+		t.Errorf("Open() with a missing browser = nil; want an error")
+	}
+}
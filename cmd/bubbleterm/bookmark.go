@@ -0,0 +1,57 @@
+package bubbleterm
+
+import (
+	"chamot/cmd/hackernews"
+	"chamot/cmd/library"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bookmarkView lists the stories bookmarked, as they were when bookmarked.
+type bookmarkView struct {
+	style   lipgloss.Style
+	model   list.Model
+	library *library.Library
+}
+
+func newBookmarkView(style lipgloss.Style, library *library.Library) *bookmarkView {
+	model := newStoryList(style, []list.Item{})
+	model.Title = "🐫 Chamot | Bookmarks"
+	model.SetStatusBarItemName("bookmark", "bookmarks")
+
+	return &bookmarkView{
+		style:   lipgloss.NewStyle().Margin(1, 2),
+		model:   model,
+		library: library,
+	}
+}
+
+func (v *bookmarkView) view() string {
+	return v.style.Render(v.model.View())
+}
+
+func (v *bookmarkView) selected() (hackernews.Story, bool) {
+	return selectedStory(v.model)
+}
+
+// load lists the bookmarks of the library, the cursor stays where it was.
+func (v *bookmarkView) load() tea.Cmd {
+	return v.model.SetItems(storyItems(v.library.Bookmarks(), nil, v.library))
+}
+
+func (v *bookmarkView) markRead(storyID int, comments bool) tea.Cmd {
+	return updateStory(&v.model, storyID, func(story *storyItem) {
+		story.read = true
+
+		if comments {
+			story.newComments = 0
+		}
+	})
+}
+
+func (v *bookmarkView) updateWindow(width int, height int) {
+	x, y := v.style.GetFrameSize()
+	v.model.SetSize(width-x, height-y)
+}
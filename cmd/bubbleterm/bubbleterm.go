@@ -1,44 +1,166 @@
 package bubbleterm
 
 import (
+	"chamot/cmd/browser"
 	"chamot/cmd/hackernews"
+	"chamot/cmd/library"
 	"chamot/cmd/ollama"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"time"
 
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+var errNoDiscussion = errors.New("no comment to summarize")
+
 const (
 	storyState int = iota
 	commentState
 	articleState
 	chatState
+	searchState
+	bookmarkState
+	userState
+	linkState
 )
 
+type feedMsg struct {
+	feed    hackernews.Feed
+	stories []hackernews.Story
+	err     error
+}
+
+type searchMsg struct {
+	query   string
+	stories []hackernews.Story
+	err     error
+}
+
+type refreshMsg struct {
+	feed    hackernews.Feed
+	stories []hackernews.Story
+	err     error
+}
+
+type refreshTickMsg struct{}
+
+type storyPageMsg struct {
+	feed    hackernews.Feed
+	page    int
+	stories []hackernews.Story
+}
+
+type commentMsg struct {
+	stream <-chan *hackernews.CommentNode
+	nodes  []*hackernews.CommentNode
+	done   bool
+}
+
+type commentErrMsg struct {
+	story  hackernews.Story
+	stream <-chan *hackernews.CommentNode
+	err    error
+}
+
+type articleMsg struct {
+	story   hackernews.Story
+	request int
+	article string
+	err     error
+}
+
+type userMsg struct {
+	userID  string
+	request int
+	user    hackernews.User
+	err     error
+}
+
+type chatMsg struct {
+	stream   <-chan ollama.Response
+	response ollama.Response
+	done     bool
+}
+
+type chatErrMsg struct {
+	messages []ollama.Message
+	text     string // Attached to the last message when it asks for a summary
+	stream   <-chan ollama.Response
+	err      error
+}
+
+// progressMsg tells how far the summary of a long text is, stream is that of the answer.
+type progressMsg struct {
+	stream   <-chan ollama.Response
+	updates  <-chan ollama.Progress
+	progress ollama.Progress
+	done     bool
+}
+
+type watchMsg struct {
+	stream <-chan hackernews.StoryUpdate
+	update hackernews.StoryUpdate
+	done   bool
+}
+
+type watchErrMsg struct {
+	feed   hackernews.Feed
+	stream <-chan hackernews.StoryUpdate
+	err    error
+}
+
+type summaryMsg struct {
+	story   hackernews.Story
+	article string
+	err     error
+}
+
+type discussionMsg struct {
+	story      hackernews.Story
+	discussion string
+	err        error
+}
+
+// chatContextMsg brings the article and the comments the chat about story is grounded on.
+type chatContextMsg struct {
+	story      hackernews.Story
+	article    string
+	discussion string
+	err        error
+}
+
 type BubbleTerm struct {
+	ctx        context.Context //nolint:containedctx // Every request derives from it, so that quitting cancels them all
+	cancel     context.CancelFunc
 	state      int
+	home       int // The list of stories the other views go back to
+	feed       hackernews.Feed
 	story      *storyView
+	bookmark   *bookmarkView
+	user       *userView
+	links      *linkView
 	comment    *commentView
 	article    *articleView
 	chat       *chatView
+	toast      *toast
+	refresh    time.Duration    // Between two refreshes of the stories, none if zero
+	library    *library.Library // Stories visited, nil to remember none
 	hackerNews hackernews.API
 	ollama     ollama.API
+	browser    browser.API
 }
 
-func NewBubbleTerm(hackerNews hackernews.API, ollama ollama.API) *BubbleTerm {
-	stories, err := hackerNews.Story()
-	if err != nil {
-		log.Fatalf("error fetching story")
-	}
-
-	items := []list.Item{}
+func NewBubbleTerm(ctx context.Context, hackerNews hackernews.API, ollama ollama.API, browser browser.API,
+	refresh time.Duration, library *library.Library,
+) *BubbleTerm {
+	feed := hackernews.TopFeed
+	ctx, cancel := context.WithCancel(ctx)
 
-	for _, story := range stories {
-		items = append(items, story)
-	}
+	stories, err := hackerNews.Story(ctx, feed)
 
 	style := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), false, false, false, true).
@@ -46,18 +168,38 @@ func NewBubbleTerm(hackerNews hackernews.API, ollama ollama.API) *BubbleTerm {
 		Foreground(lipgloss.AdaptiveColor{Light: "#FF6600", Dark: "#FF6600"}).
 		Padding(0, 0, 0, 1)
 
-	return &BubbleTerm{
+	b := &BubbleTerm{
+		ctx:        ctx,
+		cancel:     cancel,
 		hackerNews: hackerNews,
 		ollama:     ollama,
+		browser:    browser,
 		state:      storyState,
-		story:      newStoryView(style, items),
-		comment:    newCommentView(),
-		article:    newArticleView(),
+		home:       storyState,
+		feed:       feed,
+		story:      newStoryView(style, feed, stories, library),
+		bookmark:   newBookmarkView(style, library),
+		user:       newUserView(style),
+		links:      newLinkView(style),
+		comment:    newCommentView(style),
+		article:    newArticleView(style),
 		chat:       newChatView(style),
+		toast:      newToast(style),
+		refresh:    refresh,
+		library:    library,
+	}
+
+	// The app starts with an empty list, the feed can be fetched again from the toast
+	if err != nil {
+		b.toast.show(err, func() tea.Cmd { return b.loadFeed(feed) })
 	}
+
+	return b
 }
 
 func (b *BubbleTerm) Run() error {
+	defer b.cancel()
+
 	if _, err := tea.NewProgram(b, tea.WithAltScreen()).Run(); err != nil {
 		return fmt.Errorf("error running terminal app: %w", err)
 	}
@@ -66,28 +208,32 @@ func (b *BubbleTerm) Run() error {
 }
 
 func (b *BubbleTerm) Init() tea.Cmd {
-	return tea.Batch(
-		func() tea.Msg {
-			return b.ollama.Chat(b.chat.input, b.chat.output, b.chat.stop)
-		},
-		func() tea.Msg {
-			return <-b.chat.output
-		})
+	return tea.Batch(b.loadWatch(b.feed), b.tickRefresh())
 }
 
 func (b *BubbleTerm) View() string {
+	var view string
+
 	switch b.state {
 	case storyState:
-		return b.story.view()
+		view = b.story.view()
 	case commentState:
-		return b.comment.view()
+		view = b.comment.view()
 	case articleState:
-		return b.article.view()
+		view = b.article.view()
 	case chatState:
-		return b.chat.view()
-	default:
-		return ""
+		view = b.chat.view()
+	case searchState:
+		view = b.story.searchView()
+	case bookmarkState:
+		view = b.bookmark.view()
+	case userState:
+		view = b.user.view()
+	case linkState:
+		view = b.links.view()
 	}
+
+	return b.toast.view(view)
 }
 
 func (b *BubbleTerm) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint:ireturn // tea.Model is required by the lib
@@ -100,17 +246,70 @@ func (b *BubbleTerm) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint:ireturn
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
+			b.cancel()
+
 			return b, tea.Quit
 		case "ctrl+x":
+			// The search results go back to the feed they were searched from, the feed quits
+			if b.state == storyState && b.story.query != "" {
+				cmd := b.loadFeed(b.feed)
+
+				return b, cmd
+			}
+
 			if b.state == storyState {
 				return b, tea.Quit
 			}
 
-			b.state = storyState
-			b.article.gotoTop()
-			b.comment.gotoTop()
+			// The bookmarks go back to the stories, the other views to the one they were opened from
+			switch b.state {
+			case bookmarkState, searchState:
+				b.state = storyState
+				b.story.blurSearch()
+			case userState:
+				b.state = b.user.back
+				b.user.abandon()
+			case linkState:
+				b.state = b.links.back
+			case chatState:
+				b.state = b.chat.back
+			case articleState:
+				b.state = b.article.back
+				b.article.abandon()
+				b.article.gotoTop()
+			case commentState:
+				b.state = b.home
+				b.comment.abandon()
+				b.comment.gotoTop()
+			default:
+				b.state = b.home
+			}
 
 			return b, cmd
+		case "ctrl+r":
+			if b.toast.active() {
+				retry := b.toast.retry
+				b.toast.dismiss()
+
+				if retry != nil {
+					cmd = retry()
+				}
+
+				return b, cmd
+			}
+		case "esc":
+			if b.toast.active() {
+				b.toast.dismiss()
+
+				return b, cmd
+			}
+
+			if b.state == searchState {
+				b.state = storyState
+				b.story.blurSearch()
+
+				return b, cmd
+			}
 		case "ctrl+]":
 			if b.state == chatState {
 				b.chat.stopChat()
@@ -119,52 +318,197 @@ func (b *BubbleTerm) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint:ireturn
 			}
 		case "enter":
 			switch b.state {
-			case storyState:
+			case storyState, bookmarkState, userState:
+				story, ok := b.selected()
+				if !ok {
+					return b, cmd
+				}
+
+				since, visitCmd := b.visit(story)
+				b.home = b.state
 				b.state = commentState
-				story := b.story.selected()
+				cmd := b.loadComment(story, since)
 
-				comment, err := b.hackerNews.Comment(story)
-				if err != nil {
-					return b, tea.Quit
-				}
+				return b, tea.Batch(visitCmd, cmd)
+			case chatState:
+				cmd := b.loadChat(b.chat.sendPrompt())
+
+				return b, cmd
+			case searchState:
+				cmd := b.loadSearch(b.story.search.Value())
 
-				b.comment.setContent(comment)
+				return b, cmd
+			case linkState:
+				if link, ok := b.links.selected(); ok {
+					cmd := b.loadLink(link)
+
+					return b, cmd
+				}
+			}
+		case "/":
+			if b.state == storyState {
+				b.state = searchState
+				cmd := b.story.focusSearch()
 
 				return b, cmd
-			case chatState:
-				b.chat.sendPrompt()
 			}
 		case " ":
-			if b.state == storyState {
+			if story, ok := b.selected(); ok {
+				readCmd := b.read(story)
+				b.home = b.state
+				b.article.back = b.state
 				b.state = articleState
-				story := b.story.selected()
+				cmd := b.loadArticle(story)
 
-				article, err := b.hackerNews.Article(story)
-				if err != nil {
-					return b, tea.Quit
+				return b, tea.Batch(readCmd, cmd)
+			}
+		case "o":
+			// The chat is about the story at hand, a list without stories opens the chat as it is
+			if story, ok := b.current(); ok || b.state == storyState {
+				var scopeCmd tea.Cmd
+				if ok {
+					scopeCmd = b.scopeChat(story)
 				}
 
-				b.article.setContent(article)
+				b.chat.back = b.state
+				b.state = chatState
+				cmd := b.chat.focus()
+
+				return b, tea.Batch(scopeCmd, cmd)
+			}
+		case "s":
+			if story, ok := b.selected(); ok {
+				cmd := b.loadSummary(story)
 
 				return b, cmd
 			}
-		case "o":
+		case "S":
+			if story, ok := b.current(); ok {
+				cmd := b.loadDiscussion(story)
+
+				return b, cmd
+			}
+		case "b":
+			if story, ok := b.selected(); ok {
+				cmd := b.toggleBookmark(story)
+
+				return b, cmd
+			}
+		case "l":
+			if b.state == articleState || b.state == commentState {
+				cmd := b.loadLinks()
+
+				return b, cmd
+			}
+		case "x":
+			if link, ok := b.links.selected(); b.state == linkState && ok {
+				b.openBrowser(link.url)
+
+				return b, cmd
+			}
+
+			// A story without a URL, as Ask HN, is its discussion
+			if story, ok := b.current(); ok {
+				url := story.URL
+				if url == "" {
+					url = story.WebURL
+				}
+
+				b.openBrowser(url)
+
+				return b, cmd
+			}
+		case "X":
+			if story, ok := b.current(); ok && story.WebURL != "" {
+				b.openBrowser(story.WebURL)
+
+				return b, cmd
+			}
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if b.state == linkState && b.links.selectRank(msg.String()) {
+				return b, cmd
+			}
+		case "a":
+			if author, ok := b.author(); ok {
+				cmd := b.loadUser(author)
+
+				return b, cmd
+			}
+		case "B":
 			if b.state == storyState {
-				b.state = chatState
-				cmd := b.chat.focus()
+				b.state = bookmarkState
+				cmd := b.bookmark.load()
 
 				return b, cmd
 			}
-		case "s":
+		case "r":
 			if b.state == storyState {
-				story := b.story.selected()
+				cmd := b.loadRefresh(b.feed)
 
-				article, err := b.hackerNews.Article(story)
-				if err != nil {
-					return b, tea.Quit
+				return b, cmd
+			}
+		case "tab", "shift+tab":
+			if b.state == storyState {
+				feed := b.feed.Next()
+				if msg.String() == "shift+tab" {
+					feed = b.feed.Previous()
 				}
 
-				b.chat.sendArticle(article)
+				cmd := b.loadFeed(feed)
+
+				return b, cmd
+			}
+		case "J":
+			if b.state == commentState {
+				b.comment.next()
+
+				return b, cmd
+			}
+		case "K":
+			if b.state == commentState {
+				b.comment.previous()
+
+				return b, cmd
+			}
+		case "]":
+			if b.state == commentState {
+				b.comment.nextRoot()
+
+				return b, cmd
+			}
+		case "[":
+			if b.state == commentState {
+				b.comment.previousRoot()
+
+				return b, cmd
+			}
+		case "p":
+			if b.state == commentState {
+				b.comment.parent()
+
+				return b, cmd
+			}
+		case "n":
+			if b.state == commentState {
+				b.comment.nextSibling()
+
+				return b, cmd
+			}
+		case "z":
+			if b.state == commentState {
+				b.comment.toggleFold()
+
+				return b, cmd
+			}
+		case "Z":
+			if b.state == commentState {
+				b.comment.foldAll()
+
+				return b, cmd
+			}
+		case "U":
+			if b.state == commentState {
+				b.comment.unfoldAll()
 
 				return b, cmd
 			}
@@ -183,24 +527,269 @@ func (b *BubbleTerm) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint:ireturn
 				b.comment.gotoBottom()
 			}
 		}
-	case ollama.Response:
-		b.chat.formatResponse(msg, b.state == chatState)
+	case feedMsg:
+		if msg.err != nil {
+			b.toast.show(msg.err, func() tea.Cmd { return b.loadFeed(msg.feed) })
+
+			return b, cmd
+		}
+
+		b.feed = msg.feed
 
-		return b, func() tea.Msg { return <-b.chat.output }
+		return b, tea.Batch(b.story.setFeed(msg.feed, msg.stories), b.loadWatch(msg.feed))
+	case searchMsg:
+		// The search stays open, so that the query can be fixed or searched again
+		if msg.err != nil {
+			b.toast.show(msg.err, func() tea.Cmd { return b.loadSearch(msg.query) })
+
+			return b, cmd
+		}
+
+		b.state = storyState
+		b.story.blurSearch()
+		cmd := b.story.setResults(msg.query, msg.stories)
+
+		return b, cmd
+	case refreshMsg:
+		// The feed may have been switched, or searched, while it was refreshing
+		if msg.feed != b.feed || b.story.query != "" {
+			return b, cmd
+		}
+
+		if msg.err != nil {
+			b.toast.show(msg.err, func() tea.Cmd { return b.loadRefresh(msg.feed) })
+
+			return b, cmd
+		}
+
+		cmd := b.story.refresh(msg.stories)
+
+		return b, cmd
+	case refreshTickMsg:
+		// Search results are left as they are
+		if b.story.query != "" {
+			return b, b.tickRefresh()
+		}
+
+		return b, tea.Batch(b.loadRefresh(b.feed), b.tickRefresh())
+	case watchMsg:
+		// The feed may have been switched
+		if msg.stream != b.story.watch {
+			return b, cmd
+		}
+
+		if msg.done {
+			b.story.stopWatch()
+
+			return b, cmd
+		}
+
+		return b, tea.Batch(b.story.applyUpdate(msg.update), readUpdate(msg.stream))
+	case watchErrMsg:
+		if msg.stream != b.story.watch {
+			return b, cmd
+		}
+
+		b.story.stopWatch()
+		b.toast.show(msg.err, func() tea.Cmd { return b.loadWatch(msg.feed) })
+
+		return b, cmd
+	case storyPageMsg:
+		// The feed may have been switched, or searched, while the page was loading
+		if msg.feed != b.feed || b.story.query != "" {
+			return b, cmd
+		}
+
+		cmd := b.story.appendPage(msg.page, msg.stories)
+
+		return b, cmd
+	case commentMsg:
+		// The stream may have been abandoned, its fetch is cancelled
+		if msg.stream != b.comment.stream {
+			return b, cmd
+		}
+
+		b.comment.add(msg.nodes)
+
+		if msg.done {
+			b.comment.finish()
+
+			return b, cmd
+		}
+
+		return b, readComment(msg.stream)
+	case commentErrMsg:
+		if msg.stream != b.comment.stream {
+			return b, cmd
+		}
+
+		// Back to the stories, the comments can be fetched again from the toast
+		since := b.comment.since
+		b.state = b.home
+		b.comment.abandon()
+		b.comment.gotoTop()
+		b.toast.show(msg.err, func() tea.Cmd {
+			b.state = commentState
+
+			return b.loadComment(msg.story, since)
+		})
+
+		return b, cmd
+	case articleMsg:
+		// The article may have been abandoned while loading
+		if msg.request != b.article.request {
+			return b, cmd
+		}
+
+		// Back to the stories, the article can be fetched again from the toast
+		if msg.err != nil {
+			b.state = b.article.back
+			b.article.abandon()
+			b.toast.show(msg.err, func() tea.Cmd {
+				b.state = articleState
+
+				return b.loadArticle(msg.story)
+			})
+
+			return b, cmd
+		}
+
+		b.article.setContent(msg.article)
+
+		return b, cmd
+	case summaryMsg:
+		if msg.err != nil {
+			b.toast.show(msg.err, func() tea.Cmd { return b.loadSummary(msg.story) })
+
+			return b, cmd
+		}
+
+		scopeCmd := b.scopeChat(msg.story)
+		cmd := b.loadSummaryChat(b.chat.sendArticle(), msg.article)
+
+		return b, tea.Batch(scopeCmd, cmd)
+	case discussionMsg:
+		if msg.err != nil {
+			b.toast.show(msg.err, func() tea.Cmd { return b.loadDiscussion(msg.story) })
+
+			return b, cmd
+		}
+
+		if msg.discussion == "" {
+			b.toast.show(errNoDiscussion, nil)
+
+			return b, cmd
+		}
+
+		scopeCmd := b.scopeChat(msg.story)
+		cmd := b.loadSummaryChat(b.chat.sendDiscussion(msg.story), msg.discussion)
+
+		return b, tea.Batch(scopeCmd, cmd)
+	case chatContextMsg:
+		// The chat may have moved on to another story
+		if msg.story.ID != b.chat.story.ID {
+			return b, cmd
+		}
+
+		if msg.err != nil {
+			b.toast.show(msg.err, func() tea.Cmd { return b.loadChatContext(msg.story) })
+
+			return b, cmd
+		}
+
+		b.chat.setContext(msg.article, msg.discussion)
+
+		return b, cmd
+	case spinner.TickMsg:
+		return b, tea.Batch(b.article.tick(msg), b.comment.tick(msg), b.user.tick(msg))
+	case userMsg:
+		// The user may have been abandoned while loading
+		if msg.request != b.user.request {
+			return b, cmd
+		}
+
+		// Back to the stories, the user can be fetched again from the toast
+		if msg.err != nil {
+			b.state = b.user.back
+			b.user.abandon()
+			b.toast.show(msg.err, func() tea.Cmd { return b.loadUser(msg.userID) })
+
+			return b, cmd
+		}
+
+		cmd := b.user.setUser(msg.user)
+
+		return b, cmd
+	case chatMsg:
+		// The response may have been stopped, or replaced by the next one
+		if msg.stream != b.chat.stream {
+			return b, cmd
+		}
+
+		if msg.done {
+			b.chat.finish()
+
+			return b, cmd
+		}
+
+		b.chat.formatResponse(msg.response, b.state == chatState)
+
+		return b, readResponse(msg.stream)
+	case chatErrMsg:
+		if msg.stream != b.chat.stream {
+			return b, cmd
+		}
+
+		b.chat.stopChat()
+		b.toast.show(msg.err, func() tea.Cmd {
+			if msg.text != "" {
+				return b.loadSummaryChat(msg.messages, msg.text)
+			}
+
+			return b.loadChat(msg.messages)
+		})
+
+		return b, cmd
+	case progressMsg:
+		// The summary may have been stopped, or replaced by the next response
+		if msg.stream != b.chat.stream {
+			return b, cmd
+		}
+
+		// The answer is read once all the parts are summarized, never before their progress
+		if msg.done {
+			return b, readResponse(msg.stream)
+		}
+
+		b.chat.showProgress(msg.progress)
+
+		return b, readProgress(msg.stream, msg.updates)
 	case tea.WindowSizeMsg:
 		b.story.updateWindow(msg.Width, msg.Height)
 		b.comment.updateWindow(msg.Width, msg.Height)
 		b.article.updateWindow(msg.Width, msg.Height)
 		b.chat.updateWindow(msg.Width, msg.Height)
+		b.bookmark.updateWindow(msg.Width, msg.Height)
+		b.user.updateWindow(msg.Width, msg.Height)
+		b.links.updateWindow(msg.Width, msg.Height)
+		b.toast.updateWindow(msg.Width, msg.Height)
 	}
 
 	switch b.state {
 	case storyState:
 		b.story.model, cmd = b.story.model.Update(msg)
 		cmds = append(cmds, cmd)
+
+		if _, ok := msg.(tea.KeyMsg); ok {
+			cmds = append(cmds, b.loadPage())
+		}
 	case commentState:
 		b.comment.model, cmd = b.comment.model.Update(msg)
 		cmds = append(cmds, cmd)
+
+		if _, ok := msg.(tea.KeyMsg); ok {
+			b.comment.followScroll()
+		}
 	case articleState:
 		b.article.model, cmd = b.article.model.Update(msg)
 		cmds = append(cmds, cmd)
@@ -209,7 +798,338 @@ func (b *BubbleTerm) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint:ireturn
 		cmds = append(cmds, cmd)
 		b.chat.prompt, cmd = b.chat.prompt.Update(msg)
 		cmds = append(cmds, cmd)
+	case searchState:
+		b.story.search, cmd = b.story.search.Update(msg)
+		cmds = append(cmds, cmd)
+	case bookmarkState:
+		b.bookmark.model, cmd = b.bookmark.model.Update(msg)
+		cmds = append(cmds, cmd)
+	case userState:
+		b.user.model, cmd = b.user.model.Update(msg)
+		cmds = append(cmds, cmd)
+	case linkState:
+		b.links.model, cmd = b.links.model.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return b, tea.Batch(cmds...)
 }
+
+func (b *BubbleTerm) loadFeed(feed hackernews.Feed) tea.Cmd {
+	return func() tea.Msg {
+		stories, err := b.hackerNews.Story(b.ctx, feed)
+
+		return feedMsg{feed: feed, stories: stories, err: err}
+	}
+}
+
+func (b *BubbleTerm) loadRefresh(feed hackernews.Feed) tea.Cmd {
+	return func() tea.Msg {
+		stories, err := b.hackerNews.Refresh(b.ctx, feed)
+
+		return refreshMsg{feed: feed, stories: stories, err: err}
+	}
+}
+
+func (b *BubbleTerm) tickRefresh() tea.Cmd {
+	if b.refresh <= 0 {
+		return nil
+	}
+
+	return tea.Tick(b.refresh, func(time.Time) tea.Msg {
+		return refreshTickMsg{}
+	})
+}
+
+func (b *BubbleTerm) loadWatch(feed hackernews.Feed) tea.Cmd {
+	ctx, stream := b.story.watchFeed(b.ctx)
+
+	return tea.Batch(
+		func() tea.Msg {
+			// A feed left behind is not an error
+			if err := b.hackerNews.Watch(ctx, feed, stream); err != nil && ctx.Err() == nil {
+				return watchErrMsg{feed: feed, stream: stream, err: err}
+			}
+
+			return nil
+		},
+		readUpdate(stream))
+}
+
+func (b *BubbleTerm) loadSearch(query string) tea.Cmd {
+	return func() tea.Msg {
+		stories, err := b.hackerNews.Search(b.ctx, query)
+
+		return searchMsg{query: query, stories: stories, err: err}
+	}
+}
+
+func (b *BubbleTerm) loadSummary(story hackernews.Story) tea.Cmd {
+	return func() tea.Msg {
+		article, err := b.hackerNews.Article(b.ctx, story)
+
+		return summaryMsg{story: story, article: article, err: err}
+	}
+}
+
+func (b *BubbleTerm) loadDiscussion(story hackernews.Story) tea.Cmd {
+	return func() tea.Msg {
+		comments, err := b.hackerNews.Comment(b.ctx, story)
+
+		return discussionMsg{story: story, discussion: formatDiscussion(comments), err: err}
+	}
+}
+
+// scopeChat moves the chat to story, and reads what it is to be grounded on, unless the chat is
+// already about it.
+func (b *BubbleTerm) scopeChat(story hackernews.Story) tea.Cmd {
+	if !b.chat.scope(story) {
+		return nil
+	}
+
+	return b.loadChatContext(story)
+}
+
+// loadChatContext reads the article, if the story links to one, and the comments of story.
+func (b *BubbleTerm) loadChatContext(story hackernews.Story) tea.Cmd {
+	return func() tea.Msg {
+		article := ""
+
+		if story.URL != "" {
+			var err error
+			if article, err = b.hackerNews.Article(b.ctx, story); err != nil {
+				return chatContextMsg{story: story, article: "", discussion: "", err: err}
+			}
+		}
+
+		comments, err := b.hackerNews.Comment(b.ctx, story)
+
+		return chatContextMsg{story: story, article: article, discussion: formatDiscussion(comments), err: err}
+	}
+}
+
+func (b *BubbleTerm) loadPage() tea.Cmd {
+	page, ok := b.story.nextPage()
+	if !ok {
+		return nil
+	}
+
+	feed := b.feed
+
+	return func() tea.Msg {
+		// An error ends the pagination, the stories already loaded are kept
+		stories, _ := b.hackerNews.StoryPage(b.ctx, feed, page)
+
+		return storyPageMsg{feed: feed, page: page, stories: stories}
+	}
+}
+
+func (b *BubbleTerm) loadArticle(story hackernews.Story) tea.Cmd {
+	ctx, request, cmd := b.article.start(b.ctx, story)
+
+	return tea.Batch(
+		cmd,
+		func() tea.Msg {
+			article, err := b.hackerNews.Article(ctx, story)
+
+			return articleMsg{story: story, request: request, article: article, err: err}
+		})
+}
+
+// selected tells the story under the cursor of the list shown, if any.
+func (b *BubbleTerm) selected() (hackernews.Story, bool) {
+	var story hackernews.Story
+
+	switch b.state {
+	case storyState:
+		return b.story.selected()
+	case bookmarkState:
+		return b.bookmark.selected()
+	case userState:
+		return b.user.selected()
+	}
+
+	return story, false
+}
+
+// current tells the story shown, or under the cursor of the list shown, if any.
+func (b *BubbleTerm) current() (hackernews.Story, bool) {
+	switch b.state {
+	case articleState:
+		return b.article.story, true
+	case commentState:
+		return b.comment.story, true
+	}
+
+	return b.selected()
+}
+
+// author tells who posted the story or the comment under the cursor, if anyone.
+func (b *BubbleTerm) author() (string, bool) {
+	if b.state == commentState {
+		if node, ok := b.comment.current(); ok && node.By != "" {
+			return node.By, true
+		}
+
+		return "", false
+	}
+
+	story, ok := b.selected()
+
+	return story.By, ok && b.state != userState
+}
+
+// loadLinks lists the links of the article or of the comments shown.
+func (b *BubbleTerm) loadLinks() tea.Cmd {
+	links := []linkItem{}
+	title := ""
+
+	switch b.state {
+	case articleState:
+		links = b.links.find(b.article.content, b.article.story.URL, links)
+		title = b.article.story.PostTitle
+	case commentState:
+		for _, node := range b.comment.visible {
+			links = b.links.find(node.Text, b.comment.story.WebURL, links)
+		}
+
+		title = "Comments of " + b.comment.story.PostTitle
+	}
+
+	cmd := b.links.setLinks(b.state, title, links)
+	b.state = linkState
+
+	return cmd
+}
+
+// loadLink reads link as an article, it goes back to the comments it was picked from if any.
+func (b *BubbleTerm) loadLink(link linkItem) tea.Cmd {
+	if b.links.back == commentState {
+		b.article.back = commentState
+	}
+
+	b.state = articleState
+	story := hackernews.Story{ //nolint:exhaustruct // Only an article to read
+		PostTitle: link.text,
+		URL:       link.url,
+	}
+
+	return b.loadArticle(story)
+}
+
+// openBrowser hands url to the browser, a failure is only shown.
+func (b *BubbleTerm) openBrowser(url string) {
+	if err := b.browser.Open(url); err != nil {
+		b.toast.show(err, nil)
+	}
+}
+
+// loadUser opens the profile of userID, it goes back to the list of stories it is opened from.
+func (b *BubbleTerm) loadUser(userID string) tea.Cmd {
+	switch {
+	case b.state == storyState || b.state == bookmarkState:
+		b.user.back = b.state
+	case b.home != userState:
+		b.user.back = b.home
+	}
+
+	b.state = userState
+	ctx, request, cmd := b.user.start(b.ctx, userID)
+
+	return tea.Batch(
+		cmd,
+		func() tea.Msg {
+			user, err := b.hackerNews.User(ctx, userID)
+
+			return userMsg{userID: userID, request: request, user: user, err: err}
+		})
+}
+
+// visit remembers story as read along with its comments, and returns the time of the previous visit of
+// the comments, zero if none.
+func (b *BubbleTerm) visit(story hackernews.Story) (int64, tea.Cmd) {
+	previous, _ := b.library.Visit(story.ID)
+
+	// Failing to remember the visit does not prevent reading the story
+	if err := b.library.MarkVisited(story.ID, story.NumComment, time.Now()); err != nil {
+		b.toast.show(err, nil)
+	}
+
+	return previous.Time, tea.Batch(b.story.markRead(story.ID, true), b.bookmark.markRead(story.ID, true))
+}
+
+// read remembers story as read, the visit of its comments is left as it is.
+func (b *BubbleTerm) read(story hackernews.Story) tea.Cmd {
+	// Failing to remember the story does not prevent reading it
+	if err := b.library.MarkRead(story.ID, story.NumComment); err != nil {
+		b.toast.show(err, nil)
+	}
+
+	return tea.Batch(b.story.markRead(story.ID, false), b.bookmark.markRead(story.ID, false))
+}
+
+// toggleBookmark bookmarks story, or removes its bookmark, in the library and in the lists.
+func (b *BubbleTerm) toggleBookmark(story hackernews.Story) tea.Cmd {
+	bookmarked, err := b.library.ToggleBookmark(story)
+	if err != nil {
+		b.toast.show(err, func() tea.Cmd { return b.toggleBookmark(story) })
+
+		return nil
+	}
+
+	return tea.Batch(b.story.markBookmarked(story.ID, bookmarked), b.bookmark.load())
+}
+
+func (b *BubbleTerm) loadComment(story hackernews.Story, since int64) tea.Cmd {
+	stream := make(chan *hackernews.CommentNode, max(story.NumComment, 1))
+
+	ctx, cmd := b.comment.start(b.ctx, story, since, stream)
+
+	return tea.Batch(
+		cmd,
+		func() tea.Msg {
+			// An abandoned fetch is not an error
+			if err := b.hackerNews.CommentStream(ctx, story, stream); err != nil && ctx.Err() == nil {
+				return commentErrMsg{story: story, stream: stream, err: err}
+			}
+
+			return nil
+		},
+		readComment(stream))
+}
+
+// loadChat streams the answer to the last of messages, the conversation so far. A story too long
+// for the context is summarized part by part first, its progress is read to its end before the answer.
+func (b *BubbleTerm) loadChat(messages []ollama.Message) tea.Cmd {
+	ctx, stream := b.chat.start(b.ctx)
+	progress := make(chan ollama.Progress, 1)
+
+	return tea.Batch(
+		func() tea.Msg {
+			// A stopped response is not an error
+			if err := b.ollama.Chat(ctx, messages, progress, stream); err != nil && ctx.Err() == nil {
+				return chatErrMsg{messages: messages, text: "", stream: stream, err: err}
+			}
+
+			return nil
+		},
+		readProgress(stream, progress))
+}
+
+// loadSummaryChat streams the answer to the last of messages with text attached, a long text
+// is summarized part by part first. The progress is read to its end before the answer.
+func (b *BubbleTerm) loadSummaryChat(messages []ollama.Message, text string) tea.Cmd {
+	ctx, stream := b.chat.start(b.ctx)
+	progress := make(chan ollama.Progress, 1)
+
+	return tea.Batch(
+		func() tea.Msg {
+			// A stopped response is not an error
+			if err := b.ollama.Summarize(ctx, messages, text, progress, stream); err != nil && ctx.Err() == nil {
+				return chatErrMsg{messages: messages, text: text, stream: stream, err: err}
+			}
+
+			return nil
+		},
+		readProgress(stream, progress))
+}
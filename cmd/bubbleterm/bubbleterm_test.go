@@ -2,28 +2,89 @@ package bubbleterm
 
 import (
 	"chamot/cmd/hackernews"
+	"chamot/cmd/library"
 	"chamot/cmd/ollama"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type mockHackerNews struct{}
 
-func (m *mockHackerNews) Article(_ hackernews.Story) (string, error) {
+func (m *mockHackerNews) Article(_ context.Context, _ hackernews.Story) (string, error) {
 	return "Happy Birthday to the fixed point combinator that changed the world", nil
 }
 
-func (m *mockHackerNews) Comment(_ hackernews.Story) (string, error) {
-	return "World would be a very different place without YC", nil
+func (m *mockHackerNews) Comment(_ context.Context, _ hackernews.Story) ([]*hackernews.CommentNode, error) {
+	root := &hackernews.CommentNode{
+		ID:       43339316,
+		By:       "Dave_Rosenthal",
+		Text:     "World would be a very different place without YC",
+		Time:     1741746022,
+		TimeAgo:  "x days ago",
+		Depth:    0,
+		Dead:     false,
+		Deleted:  false,
+		Failed:   false,
+		Kids:     []int{43340657},
+		Parent:   nil,
+		Children: []*hackernews.CommentNode{},
+	}
+	root.Children = append(root.Children, &hackernews.CommentNode{
+		ID:       43340657,
+		By:       "knuckleheadsmif",
+		Text:     "In the mid 90s myself and 3 others worked for Intuit",
+		Time:     1741764049,
+		TimeAgo:  "x days ago",
+		Depth:    1,
+		Dead:     false,
+		Deleted:  false,
+		Failed:   false,
+		Kids:     []int{},
+		Parent:   root,
+		Children: []*hackernews.CommentNode{},
+	})
+
+	return []*hackernews.CommentNode{root}, nil
+}
+
+func (m *mockHackerNews) CommentStream(ctx context.Context, story hackernews.Story,
+	out chan<- *hackernews.CommentNode,
+) error {
+	defer close(out)
+
+	comments, _ := m.Comment(ctx, story)
+
+	var walk func(nodes []*hackernews.CommentNode)
+
+	walk = func(nodes []*hackernews.CommentNode) {
+		for _, node := range nodes {
+			children := node.Children
+			node.Children = []*hackernews.CommentNode{}
+			out <- node
+
+			walk(children)
+		}
+	}
+
+	walk(comments)
+
+	return nil
 }
 
-func (m *mockHackerNews) Story() ([]hackernews.Story, error) {
+func (m *mockHackerNews) Story(_ context.Context, _ hackernews.Feed) ([]hackernews.Story, error) {
 	return []hackernews.Story{{
 		Rank:       0,
 		By:         "btilly",
 		NumComment: 3,
+		WebURL:     "",
 		ID:         43332658,
 		Kids:       []int{43339316, 43335480},
 		Score:      1421,
@@ -35,16 +96,217 @@ func (m *mockHackerNews) Story() ([]hackernews.Story, error) {
 	}}, nil
 }
 
+// Refresh returns the story as it is on HN now, its score went up since it was cached.
+func (m *mockHackerNews) Refresh(ctx context.Context, feed hackernews.Feed) ([]hackernews.Story, error) {
+	stories, err := m.Story(ctx, feed)
+	stories[0].Score = 1500
+
+	return stories, err
+}
+
+func (m *mockHackerNews) Search(ctx context.Context, _ string) ([]hackernews.Story, error) {
+	return m.Story(ctx, hackernews.TopFeed)
+}
+
+func (m *mockHackerNews) StoryPage(ctx context.Context, feed hackernews.Feed, page int) ([]hackernews.Story, error) {
+	if page > 0 {
+		return []hackernews.Story{}, nil
+	}
+
+	return m.Story(ctx, feed)
+}
+
+func (m *mockHackerNews) Watch(_ context.Context, _ hackernews.Feed, out chan<- hackernews.StoryUpdate) error {
+	defer close(out)
+
+	out <- hackernews.StoryUpdate{IDs: []int{43332658}, Stories: []hackernews.Story{}}
+
+	return nil
+}
+
+func (m *mockHackerNews) User(ctx context.Context, userID string) (hackernews.User, error) {
+	stories, _ := m.Story(ctx, hackernews.TopFeed)
+	comments, _ := m.Comment(ctx, stories[0])
+
+	return hackernews.User{
+		ID:          userID,
+		Karma:       1421,
+		Created:     1160418092,
+		CreatedAgo:  "x years ago",
+		About:       "Founder of *Y Combinator*",
+		Submitted:   []int{43332658, 43339316},
+		Submissions: []hackernews.Submission{{Story: stories[0], Comment: nil}, {Story: stories[0], Comment: comments[0]}},
+	}, nil
+}
+
+var errMock = errors.New("mock error")
+
+// failingHackerNews fails every fetch but the search.
+type failingHackerNews struct {
+	mockHackerNews
+}
+
+func (m *failingHackerNews) Article(_ context.Context, _ hackernews.Story) (string, error) {
+	return "", errMock
+}
+
+func (m *failingHackerNews) CommentStream(_ context.Context, _ hackernews.Story,
+	out chan<- *hackernews.CommentNode,
+) error {
+	close(out)
+
+	return errMock
+}
+
+func (m *failingHackerNews) User(_ context.Context, _ string) (hackernews.User, error) {
+	var user hackernews.User
+
+	return user, errMock
+}
+
+func (m *failingHackerNews) Story(_ context.Context, _ hackernews.Feed) ([]hackernews.Story, error) {
+	return nil, errMock
+}
+
+func (m *failingHackerNews) Refresh(_ context.Context, _ hackernews.Feed) ([]hackernews.Story, error) {
+	return nil, errMock
+}
+
+// blockingHackerNews never ends a fetch of comments until it is cancelled.
+type blockingHackerNews struct {
+	mockHackerNews
+	cancelled chan struct{}
+}
+
+func (m *blockingHackerNews) CommentStream(ctx context.Context, _ hackernews.Story,
+	out chan<- *hackernews.CommentNode,
+) error {
+	defer close(out)
+
+	<-ctx.Done()
+	close(m.cancelled)
+
+	return ctx.Err()
+}
+
+// linkHackerNews serves an article with links, of a story with its URLs.
+type linkHackerNews struct {
+	mockHackerNews
+}
+
+func (m *linkHackerNews) Story(ctx context.Context, feed hackernews.Feed) ([]hackernews.Story, error) {
+	stories, err := m.mockHackerNews.Story(ctx, feed)
+	stories[0].URL = "https://www.ycombinator.com/blog/happy-20th"
+	stories[0].WebURL = "https://news.ycombinator.com/item?id=43332658"
+
+	return stories, err
+}
+
+func (m *linkHackerNews) Article(_ context.Context, _ hackernews.Story) (string, error) {
+	return "Happy Birthday to the [fixed point combinator](https://en.wikipedia.org/wiki/Fixed-point_combinator)" +
+		" from [Y Combinator](https://www.ycombinator.com)", nil
+}
+
+// fakeBrowser remembers the URLs it is given instead of opening them.
+type fakeBrowser struct {
+	opened []string
+}
+
+func (f *fakeBrowser) Open(url string) error {
+	f.opened = append(f.opened, url)
+
+	return nil
+}
+
 type mockOllama struct{}
 
-func (m *mockOllama) Chat(_ chan string, _ chan ollama.Response, _ chan bool) error {
+func (m *mockOllama) Chat(_ context.Context, _ []ollama.Message, progress chan<- ollama.Progress,
+	out chan<- ollama.Response,
+) error {
+	close(progress)
+	defer close(out)
+
+	out <- ollama.Response{
+		Message: ollama.Message{Role: ollama.RoleAssistant, Content: "Y Combinator is a startup accelerator"},
+		Done:    true,
+	}
+
 	return nil
 }
 
+// recordingOllama remembers the conversations it is sent.
+type recordingOllama struct {
+	mockOllama
+	sent chan []ollama.Message
+}
+
+func (r *recordingOllama) Chat(ctx context.Context, messages []ollama.Message, progress chan<- ollama.Progress,
+	out chan<- ollama.Response,
+) error {
+	r.sent <- messages
+
+	return r.mockOllama.Chat(ctx, messages, progress, out)
+}
+
+// condensingOllama pretends the story is long enough to be summarized in two parts before the answer.
+type condensingOllama struct {
+	mockOllama
+}
+
+func (c *condensingOllama) Chat(ctx context.Context, messages []ollama.Message, progress chan<- ollama.Progress,
+	out chan<- ollama.Response,
+) error {
+	progress <- ollama.Progress{Done: 1, Total: 2}
+	progress <- ollama.Progress{Done: 2, Total: 2}
+
+	return c.mockOllama.Chat(ctx, messages, progress, out)
+}
+
+// Summarize pretends the article is long enough to be summarized in two parts.
+func (m *mockOllama) Summarize(_ context.Context, _ []ollama.Message, _ string, progress chan<- ollama.Progress,
+	out chan<- ollama.Response,
+) error {
+	progress <- ollama.Progress{Done: 1, Total: 2}
+	progress <- ollama.Progress{Done: 2, Total: 2}
+
+	return m.Chat(context.Background(), nil, progress, out)
+}
+
+// drain runs the commands returned by Update as the bubbletea runtime would, but
+// only feeds back the messages of this package, and gives up on slow commands.
+func drain(bt *BubbleTerm, cmd tea.Cmd) {
+	msgs := make(chan tea.Msg, 10)
+	run := func(cmd tea.Cmd) {
+		if cmd != nil {
+			go func() { msgs <- cmd() }()
+		}
+	}
+
+	run(cmd)
+
+	for {
+		select {
+		case msg := <-msgs:
+			switch msg := msg.(type) {
+			case tea.BatchMsg:
+				for _, cmd := range msg {
+					run(cmd)
+				}
+			case refreshMsg, userMsg, watchMsg, watchErrMsg, chatMsg, chatErrMsg, progressMsg, feedMsg, searchMsg,
+				storyPageMsg, commentMsg, commentErrMsg, articleMsg, summaryMsg, discussionMsg, chatContextMsg:
+				_, cmd := bt.Update(msg)
+				run(cmd)
+			}
+		case <-time.After(50 * time.Millisecond):
+			return
+		}
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	hn := &mockHackerNews{}
 	ol := &mockOllama{}
-	bt := NewBubbleTerm(hn, ol)
+	bt := NewBubbleTerm(context.Background(), hn, ol, &fakeBrowser{opened: []string{}}, 0, nil)
 
 	bt.Init() // Nothing happens
 
@@ -62,12 +324,82 @@ func TestUpdate(t *testing.T) {
 			expectedViewContains: "Happy 20th birthday, Y Combinator",
 			expectedCmdIsNil:     true,
 		},
+		{
+			name:                 "tab key switches to the next feed",
+			input:                tea.KeyMsg{Type: tea.KeyTab, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Chamot | New",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "shift+tab key switches back to the previous feed",
+			input:                tea.KeyMsg{Type: tea.KeyShiftTab, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Chamot | Top",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "r key refreshes the stories",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r"), Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "1500 points",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "j key loads the next page",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j"), Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "Next page appends nothing when the feed is exhausted",
+			input:                storyPageMsg{feed: hackernews.TopFeed, page: 1, stories: []hackernews.Story{}},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "k key does not load the exhausted feed again",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k"), Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "/ key moves to state 4",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/"), Alt: false, Paste: false},
+			expectedState:        4,
+			expectedViewContains: "Search stories...",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "Type 'birthday' keeps to state 4",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("birthday"), Alt: false, Paste: false},
+			expectedState:        4,
+			expectedViewContains: "birthday",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "Enter shows the search results in state 0",
+			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Search: birthday",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "ctrl+x key leaves the search results for the feed",
+			input:                tea.KeyMsg{Type: tea.KeyCtrlX, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Chamot | Top",
+			expectedCmdIsNil:     false,
+		},
 		{
 			name:                 "Enter key moves to state 1",
 			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("enter"), Alt: false, Paste: false},
 			expectedState:        1,
 			expectedViewContains: "World would be a very different place without YC",
-			expectedCmdIsNil:     true,
+			expectedCmdIsNil:     false,
 		},
 		{
 			name:                 "g key keeps to state 1",
@@ -83,6 +415,41 @@ func TestUpdate(t *testing.T) {
 			expectedViewContains: "World would be a very different place without YC",
 			expectedCmdIsNil:     true,
 		},
+		{
+			name:                 "z key folds the replies of the comment",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z"), Alt: false, Paste: false},
+			expectedState:        1,
+			expectedViewContains: "[+1 reply]",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "U key unfolds all the comments",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U"), Alt: false, Paste: false},
+			expectedState:        1,
+			expectedViewContains: "worked for Intuit",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "J key moves the cursor to the reply",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J"), Alt: false, Paste: false},
+			expectedState:        1,
+			expectedViewContains: "worked for Intuit",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "Z key folds all the comments",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z"), Alt: false, Paste: false},
+			expectedState:        1,
+			expectedViewContains: "[+1 reply]",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "K key keeps the cursor on the first comment",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K"), Alt: false, Paste: false},
+			expectedState:        1,
+			expectedViewContains: "World would be a very different place without YC",
+			expectedCmdIsNil:     true,
+		},
 		{
 			name:                 "ctrl+x key moves to state 0",
 			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
@@ -95,7 +462,7 @@ func TestUpdate(t *testing.T) {
 			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" "), Alt: false, Paste: false},
 			expectedState:        2,
 			expectedViewContains: "Happy Birthday to the fixed point combinator that changed the world",
-			expectedCmdIsNil:     true,
+			expectedCmdIsNil:     false,
 		},
 		{
 			name:                 "g key keeps to state 2",
@@ -118,6 +485,41 @@ func TestUpdate(t *testing.T) {
 			expectedViewContains: "Happy 20th birthday, Y Combinator",
 			expectedCmdIsNil:     true,
 		},
+		{
+			name:                 "Abandoned article is not shown",
+			input:                articleMsg{request: 0, article: "Abandoned article", err: nil},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "a key moves to state 6 and shows the author",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: false, Paste: false},
+			expectedState:        6,
+			expectedViewContains: "Founder of",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "Enter key shows the comments of the submission in state 1",
+			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        1,
+			expectedViewContains: "World would be a very different place without YC",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "ctrl+x key moves back to state 6",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
+			expectedState:        6,
+			expectedViewContains: "💬 on Happy 20th birthday",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "ctrl+x key moves back to state 0",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     true,
+		},
 		{
 			name:                 "o key moves to state 3",
 			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: false, Paste: false},
@@ -126,11 +528,11 @@ func TestUpdate(t *testing.T) {
 			expectedCmdIsNil:     false,
 		},
 		{
-			name:                 "Enter keeps to state 3",
+			name:                 "Enter keeps to state 3 and shows the response",
 			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
 			expectedState:        3,
-			expectedViewContains: "Send a message",
-			expectedCmdIsNil:     true,
+			expectedViewContains: "startup accelerator",
+			expectedCmdIsNil:     false,
 		},
 		{
 			name:                 "Type 'hello' keeps to state 3",
@@ -158,6 +560,41 @@ func TestUpdate(t *testing.T) {
 			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s"), Alt: false, Paste: false},
 			expectedState:        0,
 			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "o key shows the summary",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: false, Paste: false},
+			expectedState:        3,
+			expectedViewContains: "startup accelerator",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "ctrl+x key moves back to state 0",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     true,
+		},
+		{
+			name:                 "S key keeps to state 0",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S"), Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "o key shows the summary of the discussion",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: false, Paste: false},
+			expectedState:        3,
+			expectedViewContains: "Summarizing the discussion",
+			expectedCmdIsNil:     false,
+		},
+		{
+			name:                 "ctrl+x key moves back to state 0 again",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
+			expectedState:        0,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
 			expectedCmdIsNil:     true,
 		},
 		{
@@ -173,6 +610,7 @@ func TestUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			model, cmd := bt.Update(tt.input)
 			bt = model.(*BubbleTerm)
+			drain(bt, cmd)
 			view := bt.View()
 
 			if bt.state != tt.expectedState {
@@ -189,3 +627,680 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateBookmark(t *testing.T) {
+	lib, err := library.NewLibrary(filepath.Join(t.TempDir(), "library.json"))
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+
+	hn := &mockHackerNews{}
+	ol := &mockOllama{}
+	bt := NewBubbleTerm(context.Background(), hn, ol, &fakeBrowser{opened: []string{}}, 0, lib)
+	bt.Update(tea.WindowSizeMsg{Width: 80, Height: 80})
+
+	tests := []struct {
+		name                 string
+		input                tea.Msg
+		expectedState        int
+		expectedViewContains string
+		expectedBookmarks    int
+	}{
+		{
+			name:                 "b key bookmarks the story",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: false, Paste: false},
+			expectedState:        storyState,
+			expectedViewContains: "🔖",
+			expectedBookmarks:    1,
+		},
+		{
+			name:                 "B key lists the bookmarks",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B"), Alt: false, Paste: false},
+			expectedState:        bookmarkState,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedBookmarks:    1,
+		},
+		{
+			name:                 "Enter key shows the comments of the bookmark",
+			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        commentState,
+			expectedViewContains: "World would be a very different place without YC",
+			expectedBookmarks:    1,
+		},
+		{
+			name:                 "ctrl+x key goes back to the bookmarks",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
+			expectedState:        bookmarkState,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedBookmarks:    1,
+		},
+		{
+			name:                 "b key removes the bookmark",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: false, Paste: false},
+			expectedState:        bookmarkState,
+			expectedViewContains: "No bookmarks",
+			expectedBookmarks:    0,
+		},
+		{
+			name:                 "ctrl+x key goes back to the stories",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
+			expectedState:        storyState,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedBookmarks:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, cmd := bt.Update(tt.input)
+			bt = model.(*BubbleTerm)
+			drain(bt, cmd)
+			view := bt.View()
+
+			if bt.state != tt.expectedState {
+				t.Errorf("Expected state to be %d, got %d", tt.expectedState, bt.state)
+			}
+
+			if !strings.Contains(view, tt.expectedViewContains) {
+				t.Errorf("Expected view to contain '%s', got %v", tt.expectedViewContains, view)
+			}
+
+			if bookmarks := lib.Bookmarks(); len(bookmarks) != tt.expectedBookmarks {
+				t.Errorf("Expected %d bookmarks, got %v", tt.expectedBookmarks, bookmarks)
+			}
+		})
+	}
+}
+
+func TestUpdateVisit(t *testing.T) {
+	lib, err := library.NewLibrary(filepath.Join(t.TempDir(), "library.json"))
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+
+	// The comments were last read between the root comment and its reply
+	previous := library.Visit{NumComment: 1, Time: 1741750000}
+	if err := lib.MarkVisited(43332658, previous.NumComment, time.Unix(previous.Time, 0)); err != nil {
+		t.Fatalf("MarkVisited() error = %v", err)
+	}
+
+	hn := &mockHackerNews{}
+	ol := &mockOllama{}
+	bt := NewBubbleTerm(context.Background(), hn, ol, &fakeBrowser{opened: []string{}}, 0, lib)
+	bt.Update(tea.WindowSizeMsg{Width: 120, Height: 80})
+
+	tests := []struct {
+		name                 string
+		input                tea.Msg
+		expectedState        int
+		expectedViewContains string
+		expectedVisit        func(visit library.Visit) bool
+	}{
+		{
+			name:                 "Space key reads the article, the visit of the comments is kept",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" "), Alt: false, Paste: false},
+			expectedState:        articleState,
+			expectedViewContains: "fixed point combinator",
+			expectedVisit:        func(visit library.Visit) bool { return visit == previous },
+		},
+		{
+			name:                 "ctrl+x key goes back to the stories",
+			input:                tea.KeyMsg{Type: tea.KeyCtrlX, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        storyState,
+			expectedViewContains: "+2 new comments",
+			expectedVisit:        func(visit library.Visit) bool { return visit == previous },
+		},
+		{
+			name:                 "Enter key highlights the comments posted since the previous visit",
+			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        commentState,
+			expectedViewContains: "✨",
+			expectedVisit:        func(visit library.Visit) bool { return visit.NumComment == 3 && visit.Time > previous.Time },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, cmd := bt.Update(tt.input)
+			bt = model.(*BubbleTerm)
+			drain(bt, cmd)
+			view := bt.View()
+
+			if bt.state != tt.expectedState {
+				t.Errorf("Expected state to be %d, got %d", tt.expectedState, bt.state)
+			}
+
+			if !strings.Contains(view, tt.expectedViewContains) {
+				t.Errorf("Expected view to contain '%s', got %v", tt.expectedViewContains, view)
+			}
+
+			if visit, _ := lib.Visit(43332658); !tt.expectedVisit(visit) {
+				t.Errorf("Unexpected visit %+v", visit)
+			}
+		})
+	}
+}
+
+// TestUpdateEsc checks that the keys the lists bind to quitting by default leave the app running.
+func TestUpdateEsc(t *testing.T) {
+	tests := []struct {
+		name          string
+		keys          []tea.KeyMsg // Open the view
+		expectedState int
+	}{
+		{
+			name:          "bookmarks",
+			keys:          []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("B"), Alt: false, Paste: false}},
+			expectedState: bookmarkState,
+		},
+		{
+			name:          "author",
+			keys:          []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("a"), Alt: false, Paste: false}},
+			expectedState: userState,
+		},
+		{
+			name: "links",
+			keys: []tea.KeyMsg{
+				{Type: tea.KeyRunes, Runes: []rune(" "), Alt: false, Paste: false},
+				{Type: tea.KeyRunes, Runes: []rune("l"), Alt: false, Paste: false},
+			},
+			expectedState: linkState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hn := &linkHackerNews{mockHackerNews: mockHackerNews{}}
+			bt := NewBubbleTerm(context.Background(), hn, &mockOllama{}, &fakeBrowser{opened: []string{}}, 0, nil)
+			bt.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+			for _, key := range tt.keys {
+				_, cmd := bt.Update(key)
+				drain(bt, cmd)
+			}
+
+			for _, key := range []tea.KeyMsg{
+				{Type: tea.KeyEscape, Runes: []rune{}, Alt: false, Paste: false},
+				{Type: tea.KeyRunes, Runes: []rune("q"), Alt: false, Paste: false},
+			} {
+				if _, cmd := bt.Update(key); cmd != nil {
+					if _, ok := cmd().(tea.QuitMsg); ok {
+						t.Errorf("Expected %s key not to quit", key)
+					}
+				}
+
+				if bt.state != tt.expectedState {
+					t.Errorf("Expected state to be %d after %s key, got %d", tt.expectedState, key, bt.state)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateLink(t *testing.T) {
+	hn := &linkHackerNews{mockHackerNews: mockHackerNews{}}
+	ol := &mockOllama{}
+	browser := &fakeBrowser{opened: []string{}}
+	bt := NewBubbleTerm(context.Background(), hn, ol, browser, 0, nil)
+	bt.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	tests := []struct {
+		name                 string
+		input                tea.Msg
+		expectedState        int
+		expectedViewContains string
+		expectedOpened       []string
+	}{
+		{
+			name:                 "Space key shows the article",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" "), Alt: false, Paste: false},
+			expectedState:        articleState,
+			expectedViewContains: "fixed point combinator",
+			expectedOpened:       []string{},
+		},
+		{
+			name:                 "l key lists the links of the article",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l"), Alt: false, Paste: false},
+			expectedState:        linkState,
+			expectedViewContains: "2. Y Combinator",
+			expectedOpened:       []string{},
+		},
+		{
+			name:                 "2 key moves the cursor to the second link",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2"), Alt: false, Paste: false},
+			expectedState:        linkState,
+			expectedViewContains: "https://www.ycombinator.com",
+			expectedOpened:       []string{},
+		},
+		{
+			name:                 "x key opens the link in the browser",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: false, Paste: false},
+			expectedState:        linkState,
+			expectedViewContains: "https://www.ycombinator.com",
+			expectedOpened:       []string{"https://www.ycombinator.com"},
+		},
+		{
+			name:                 "Enter key reads the link as an article",
+			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        articleState,
+			expectedViewContains: "fixed point combinator",
+			expectedOpened:       []string{"https://www.ycombinator.com"},
+		},
+		{
+			name:                 "ctrl+x key goes back to the stories",
+			input:                tea.KeyMsg{Type: tea.KeyCtrlX, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        storyState,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedOpened:       []string{"https://www.ycombinator.com"},
+		},
+		{
+			name:                 "Enter key shows the comments",
+			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        commentState,
+			expectedViewContains: "World would be a very different place without YC",
+			expectedOpened:       []string{"https://www.ycombinator.com"},
+		},
+		{
+			name:                 "l key lists the links of the comments",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l"), Alt: false, Paste: false},
+			expectedState:        linkState,
+			expectedViewContains: "No links",
+			expectedOpened:       []string{"https://www.ycombinator.com"},
+		},
+		{
+			name:                 "ctrl+x key goes back to the comments",
+			input:                tea.KeyMsg{Type: tea.KeyCtrlX, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        commentState,
+			expectedViewContains: "World would be a very different place without YC",
+			expectedOpened:       []string{"https://www.ycombinator.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, cmd := bt.Update(tt.input)
+			bt = model.(*BubbleTerm)
+			drain(bt, cmd)
+			view := bt.View()
+
+			if bt.state != tt.expectedState {
+				t.Errorf("Expected state to be %d, got %d", tt.expectedState, bt.state)
+			}
+
+			if !strings.Contains(view, tt.expectedViewContains) {
+				t.Errorf("Expected view to contain '%s', got %v", tt.expectedViewContains, view)
+			}
+
+			if !slices.Equal(browser.opened, tt.expectedOpened) {
+				t.Errorf("Expected the browser to open %v, got %v", tt.expectedOpened, browser.opened)
+			}
+		})
+	}
+}
+
+func TestUpdateChat(t *testing.T) {
+	hn := &linkHackerNews{mockHackerNews: mockHackerNews{}}
+	ol := &recordingOllama{mockOllama: mockOllama{}, sent: make(chan []ollama.Message, 10)}
+	bt := NewBubbleTerm(context.Background(), hn, ol, &fakeBrowser{opened: []string{}}, 0, nil)
+	bt.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	tests := []struct {
+		name                 string
+		input                tea.Msg
+		expectedState        int
+		expectedViewContains string
+		expectedSent         []string // Contents sent, in order, nil if nothing
+	}{
+		{
+			name:                 "o key opens the chat about the story",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: false, Paste: false},
+			expectedState:        chatState,
+			expectedViewContains: "Cha(t)mot | Happy 20th birthday, Y Combinator",
+			expectedSent:         nil,
+		},
+		{
+			name:                 "article and comments attached",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("pricing?"), Alt: false, Paste: false},
+			expectedState:        chatState,
+			expectedViewContains: "ask away",
+			expectedSent:         nil,
+		},
+		{
+			name:                 "enter key asks about the story",
+			input:                tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:        chatState,
+			expectedViewContains: "Y Combinator is a startup accelerator",
+			expectedSent: []string{
+				"You are Cha(t)mot",
+				`The user is reading the Hacker News story "Happy 20th birthday, Y Combinator"`,
+				"Article:\n\nHappy Birthday to the [fixed point combinator]",
+				"- Dave_Rosenthal: World would be a very different place without YC\n" +
+					"  - knuckleheadsmif: In the mid 90s",
+				"pricing?",
+			},
+		},
+		{
+			name:                 "ctrl+x key moves back to the stories",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ctrl+x"), Alt: false, Paste: false},
+			expectedState:        storyState,
+			expectedViewContains: "Happy 20th birthday, Y Combinator",
+			expectedSent:         nil,
+		},
+		{
+			name:                 "o key goes on with the chat about the same story",
+			input:                tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: false, Paste: false},
+			expectedState:        chatState,
+			expectedViewContains: "Y Combinator is a startup accelerator",
+			expectedSent:         nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, cmd := bt.Update(tt.input)
+			bt = model.(*BubbleTerm)
+			drain(bt, cmd)
+			view := bt.View()
+
+			if bt.state != tt.expectedState {
+				t.Errorf("Expected state to be %d, got %d", tt.expectedState, bt.state)
+			}
+
+			if !strings.Contains(view, tt.expectedViewContains) {
+				t.Errorf("Expected view to contain '%s', got %v", tt.expectedViewContains, view)
+			}
+
+			if tt.expectedSent == nil {
+				return
+			}
+
+			sent := <-ol.sent
+			if len(sent) != len(tt.expectedSent) {
+				t.Fatalf("Expected %d messages sent, got %v", len(tt.expectedSent), sent)
+			}
+
+			for i, expected := range tt.expectedSent {
+				if !strings.Contains(sent[i].Content, expected) {
+					t.Errorf("Expected message %d to contain %q, got %q", i, expected, sent[i].Content)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateSummaryChat(t *testing.T) {
+	messages := []ollama.Message{{Role: ollama.RoleUser, Content: "Summarize this article."}}
+
+	tests := []struct {
+		name   string
+		ollama ollama.API
+		load   func(bt *BubbleTerm) tea.Cmd
+	}{
+		{
+			name:   "long article summarized",
+			ollama: &mockOllama{},
+			load: func(bt *BubbleTerm) tea.Cmd {
+				return bt.loadSummaryChat(messages, "The end.")
+			},
+		},
+		{
+			name:   "long story condensed",
+			ollama: &condensingOllama{mockOllama: mockOllama{}},
+			load: func(bt *BubbleTerm) tea.Cmd {
+				return bt.loadChat(messages)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bt := NewBubbleTerm(context.Background(), &mockHackerNews{}, tt.ollama, &fakeBrowser{opened: []string{}}, 0,
+				nil)
+			bt.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+			bt.state = chatState // The answer is rendered as it comes
+
+			batch := tt.load(bt)().(tea.BatchMsg)
+
+			go batch[0]()
+
+			// Each message is read once the previous one is handled, as the runtime would, none is waited for
+			received := []string{}
+
+			for read := batch[1]; read != nil; {
+				msg := read()
+
+				switch msg := msg.(type) {
+				case progressMsg:
+					received = append(received, fmt.Sprintf("progress done=%t", msg.done))
+				case chatMsg:
+					received = append(received, fmt.Sprintf("answer done=%t", msg.done))
+				}
+
+				_, read = bt.Update(msg)
+			}
+
+			expected := []string{
+				"progress done=false", "progress done=false", "progress done=true", "answer done=false",
+				"answer done=true",
+			}
+			if !slices.Equal(received, expected) {
+				t.Errorf("Expected the messages %v, got %v", expected, received)
+			}
+
+			view := bt.chat.model.View()
+
+			part, answer := strings.Index(view, "Part 2 of 2 summarized"), strings.Index(view, "startup accelerator")
+			if part < 0 || answer < part {
+				t.Errorf("Expected the progress of the summary then the answer, got %v", view)
+			}
+		})
+	}
+}
+
+func TestUpdateBrowser(t *testing.T) {
+	const (
+		url    = "https://www.ycombinator.com/blog/happy-20th"
+		webURL = "https://news.ycombinator.com/item?id=43332658"
+	)
+
+	hn := &linkHackerNews{mockHackerNews: mockHackerNews{}}
+	ol := &mockOllama{}
+	browser := &fakeBrowser{opened: []string{}}
+	bt := NewBubbleTerm(context.Background(), hn, ol, browser, 0, nil)
+	bt.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	tests := []struct {
+		name           string
+		input          tea.Msg
+		expectedState  int
+		expectedOpened string // Last URL opened
+	}{
+		{
+			name:           "x key opens the story",
+			input:          tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: false, Paste: false},
+			expectedState:  storyState,
+			expectedOpened: url,
+		},
+		{
+			name:           "X key opens the discussion of the story",
+			input:          tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("X"), Alt: false, Paste: false},
+			expectedState:  storyState,
+			expectedOpened: webURL,
+		},
+		{
+			name:           "Space key shows the article",
+			input:          tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" "), Alt: false, Paste: false},
+			expectedState:  articleState,
+			expectedOpened: webURL,
+		},
+		{
+			name:           "x key opens the article",
+			input:          tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: false, Paste: false},
+			expectedState:  articleState,
+			expectedOpened: url,
+		},
+		{
+			name:           "ctrl+x key goes back to the stories",
+			input:          tea.KeyMsg{Type: tea.KeyCtrlX, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:  storyState,
+			expectedOpened: url,
+		},
+		{
+			name:           "Enter key shows the comments",
+			input:          tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:  commentState,
+			expectedOpened: url,
+		},
+		{
+			name:           "X key opens the discussion of the comments",
+			input:          tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("X"), Alt: false, Paste: false},
+			expectedState:  commentState,
+			expectedOpened: webURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, cmd := bt.Update(tt.input)
+			bt = model.(*BubbleTerm)
+			drain(bt, cmd)
+
+			if bt.state != tt.expectedState {
+				t.Errorf("Expected state to be %d, got %d", tt.expectedState, bt.state)
+			}
+
+			if len(browser.opened) == 0 || browser.opened[len(browser.opened)-1] != tt.expectedOpened {
+				t.Errorf("Expected the browser to open %s last, got %v", tt.expectedOpened, browser.opened)
+			}
+		})
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	hn := &failingHackerNews{}
+	ol := &mockOllama{}
+	bt := NewBubbleTerm(context.Background(), hn, ol, &fakeBrowser{opened: []string{}}, 0, nil)
+	bt.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	tests := []struct {
+		name                    string
+		input                   tea.Msg
+		expectedState           int
+		expectedViewContains    string
+		expectedViewNotContains string
+	}{
+		{
+			name:                    "the story error is shown instead of quitting",
+			input:                   nil,
+			expectedState:           0,
+			expectedViewContains:    "mock error",
+			expectedViewNotContains: "",
+		},
+		{
+			name:                    "esc key dismisses the error",
+			input:                   tea.KeyMsg{Type: tea.KeyEscape, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "Chamot | Top",
+			expectedViewNotContains: "mock error",
+		},
+		{
+			name:                    "/ key moves to state 4",
+			input:                   tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/"), Alt: false, Paste: false},
+			expectedState:           4,
+			expectedViewContains:    "🔎",
+			expectedViewNotContains: "mock error",
+		},
+		{
+			name:                    "Enter shows the search results",
+			input:                   tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "Happy 20th birthday, Y Combinator",
+			expectedViewNotContains: "mock error",
+		},
+		{
+			name:                    "Space key error keeps to state 0",
+			input:                   tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" "), Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "ctrl+r retry",
+			expectedViewNotContains: "",
+		},
+		{
+			name:                    "ctrl+r key retries the article",
+			input:                   tea.KeyMsg{Type: tea.KeyCtrlR, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "mock error",
+			expectedViewNotContains: "",
+		},
+		{
+			name:                    "Enter key error keeps to state 0",
+			input:                   tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "mock error",
+			expectedViewNotContains: "",
+		},
+		{
+			name:                    "s key error keeps to state 0",
+			input:                   tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s"), Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "mock error",
+			expectedViewNotContains: "",
+		},
+		{
+			name:                    "S key error keeps to state 0",
+			input:                   tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S"), Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "mock error",
+			expectedViewNotContains: "",
+		},
+		{
+			name:                    "a key error keeps to state 0",
+			input:                   tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: false, Paste: false},
+			expectedState:           0,
+			expectedViewContains:    "mock error",
+			expectedViewNotContains: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.input != nil {
+				model, cmd := bt.Update(tt.input)
+				bt = model.(*BubbleTerm)
+				drain(bt, cmd)
+			}
+
+			view := bt.View()
+
+			if bt.state != tt.expectedState {
+				t.Errorf("Expected state to be %d, got %d", tt.expectedState, bt.state)
+			}
+
+			if !strings.Contains(view, tt.expectedViewContains) {
+				t.Errorf("Expected view to contain '%s', got %v", tt.expectedViewContains, view)
+			}
+
+			if tt.expectedViewNotContains != "" && strings.Contains(view, tt.expectedViewNotContains) {
+				t.Errorf("Expected view not to contain '%s', got %v", tt.expectedViewNotContains, view)
+			}
+		})
+	}
+}
+
+func TestUpdateCancel(t *testing.T) {
+	hn := &blockingHackerNews{mockHackerNews: mockHackerNews{}, cancelled: make(chan struct{})}
+	ol := &mockOllama{}
+	bt := NewBubbleTerm(context.Background(), hn, ol, &fakeBrowser{opened: []string{}}, 0, nil)
+
+	_, cmd := bt.Update(tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}, Alt: false, Paste: false})
+	for _, cmd := range cmd().(tea.BatchMsg) {
+		go cmd()
+	}
+
+	bt.Update(tea.KeyMsg{Type: tea.KeyCtrlX, Runes: []rune{}, Alt: false, Paste: false})
+
+	select {
+	case <-hn.cancelled:
+	case <-time.After(time.Second):
+		t.Fatalf("Leaving the comments did not cancel their fetch")
+	}
+}
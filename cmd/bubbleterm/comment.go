@@ -0,0 +1,557 @@
+package bubbleterm
+
+import (
+	"chamot/cmd/hackernews"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// commentView renders each comment on its own to know where it starts in the
+// viewport, which is what the cursor and the folding rely on.
+type commentView struct {
+	style      lipgloss.Style
+	model      viewport.Model
+	cursor     lipgloss.Style
+	spinner    spinner.Model
+	renderer   *glamour.TermRenderer
+	linkRegexp *regexp.Regexp // Keep this here to avoid recompiling each time
+	header     string
+	story      hackernews.Story
+	comments   []*hackernews.CommentNode
+	thread     *hackernews.Thread
+	stream     <-chan *hackernews.CommentNode
+	cancel     context.CancelFunc // Stops the fetch of stream
+	since      int64              // Unix time of the previous visit, the comments posted after it are new
+	fetched    int
+	total      int
+	loading    bool
+	visible    []*hackernews.CommentNode
+	offsets    []int // First line of each visible comment
+	selected   int   // Index of the cursor in visible
+	folded     map[int]bool
+	blocks     map[int]string // Rendered comments, the layout is redone on each change
+}
+
+func newCommentView(style lipgloss.Style) *commentView {
+	// Without a renderer the comments are shown as raw markdown
+	renderer, _ := glamour.NewTermRenderer(glamour.WithEnvironmentConfig())
+
+	return &commentView{
+		style:      lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).Padding(0, 1),
+		model:      viewport.New(0, 0),
+		cursor:     lipgloss.NewStyle().Foreground(style.GetForeground()),
+		spinner:    newSpinner(style),
+		renderer:   renderer,
+		linkRegexp: regexp.MustCompile(`\[(.*?)\]\((.*?)\)`),
+		header:     "",
+		story:      hackernews.Story{}, //nolint:exhaustruct // No story yet
+		comments:   []*hackernews.CommentNode{},
+		thread:     nil,
+		stream:     nil,
+		cancel:     func() {},
+		since:      0,
+		fetched:    0,
+		total:      0,
+		loading:    false,
+		visible:    []*hackernews.CommentNode{},
+		offsets:    []int{},
+		selected:   0,
+		folded:     map[int]bool{},
+		blocks:     map[int]string{},
+	}
+}
+
+func (c *commentView) headerView() string {
+	return lipgloss.JoinHorizontal(lipgloss.Center, strings.Repeat(" ", max(0, c.model.Width)))
+}
+
+func (c *commentView) footerView() string {
+	info := c.style.Render(fmt.Sprintf("%3.f%%", c.model.ScrollPercent()*100))
+	progress := ""
+
+	if c.loading {
+		progress = c.style.Render(fmt.Sprintf("%s 🐮 %d/%d", c.spinner.View(), c.fetched, c.total))
+	}
+
+	line := strings.Repeat(" ", max(0, c.model.Width-lipgloss.Width(progress)-lipgloss.Width(info)))
+
+	return lipgloss.JoinHorizontal(lipgloss.Center, progress, line, info)
+}
+
+func (c *commentView) view() string {
+	return fmt.Sprintf("%s\n%s\n%s", c.headerView(), c.model.View(), c.footerView())
+}
+
+func (c *commentView) gotoTop() {
+	c.model.SetYOffset(0)
+}
+
+func (c *commentView) gotoBottom() {
+	c.model.SetYOffset(c.model.TotalLineCount())
+}
+
+func (c *commentView) setContent(story hackernews.Story, comments []*hackernews.CommentNode) {
+	c.reset(story)
+	c.comments = comments
+	c.layout(nil)
+}
+
+// start shows an empty thread, add fills it as the comments are read from stream.
+// The comments posted after since are highlighted, none if it is zero.
+// It returns the context of the fetch, cancelled once abandoned, and the command animating the spinner.
+func (c *commentView) start(ctx context.Context, story hackernews.Story, since int64,
+	stream <-chan *hackernews.CommentNode,
+) (context.Context, tea.Cmd) {
+	c.abandon()
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.reset(story)
+	c.since = since
+	c.thread = hackernews.NewThread(story)
+	c.stream = stream
+	c.total = story.NumComment
+	c.loading = true
+	c.layout(nil)
+
+	return ctx, c.spinner.Tick
+}
+
+func (c *commentView) add(nodes []*hackernews.CommentNode) {
+	anchor, ok := c.current()
+	before := c.model.YOffset
+
+	if ok {
+		before = c.offsets[c.selected]
+	}
+
+	for _, node := range nodes {
+		c.thread.Insert(node)
+	}
+
+	c.fetched += len(nodes)
+	c.comments = c.thread.Comments
+
+	// Nothing moves for a reader at the top, otherwise the comment under the cursor stays in place
+	if !ok || c.model.YOffset == 0 {
+		c.layout(nil)
+
+		return
+	}
+
+	c.layout(anchor)
+	c.model.SetYOffset(c.model.YOffset + c.offsets[c.selected] - before)
+}
+
+func (c *commentView) finish() {
+	c.loading = false
+	c.cancel()
+}
+
+// abandon stops the fetch of the stream, the comments already there are kept.
+func (c *commentView) abandon() {
+	c.loading = false
+	c.stream = nil
+	c.cancel()
+}
+
+func (c *commentView) tick(msg spinner.TickMsg) tea.Cmd {
+	if !c.loading {
+		return nil
+	}
+
+	var cmd tea.Cmd
+
+	c.spinner, cmd = c.spinner.Update(msg)
+
+	return cmd
+}
+
+func (c *commentView) reset(story hackernews.Story) {
+	c.header = c.render(fmt.Sprintf("**| 🐮 %d Co(w)mments [%s]**", story.NumComment, story.WebURL))
+	c.story = story
+	c.comments = []*hackernews.CommentNode{}
+	c.thread = nil
+	c.stream = nil
+	c.fetched = 0
+	c.total = 0
+	c.loading = false
+	c.selected = 0
+	c.folded = map[int]bool{}
+	c.blocks = map[int]string{}
+}
+
+// next moves the cursor to the next visible comment.
+func (c *commentView) next() {
+	c.moveTo(c.selected + 1)
+}
+
+// previous moves the cursor to the previous visible comment.
+func (c *commentView) previous() {
+	c.moveTo(c.selected - 1)
+}
+
+// nextRoot moves the cursor to the next top-level comment.
+func (c *commentView) nextRoot() {
+	for i := c.selected + 1; i < len(c.visible); i++ {
+		if c.visible[i].Parent == nil {
+			c.moveTo(i)
+
+			return
+		}
+	}
+}
+
+// previousRoot moves the cursor to the top-level comment before it, the one of its thread for a reply.
+func (c *commentView) previousRoot() {
+	for i := c.selected - 1; i >= 0; i-- {
+		if c.visible[i].Parent == nil {
+			c.moveTo(i)
+
+			return
+		}
+	}
+}
+
+func (c *commentView) parent() {
+	node, ok := c.current()
+	if !ok || node.Parent == nil {
+		return
+	}
+
+	if i, ok := c.indexOf(node.Parent); ok {
+		c.moveTo(i)
+	}
+}
+
+// nextSibling moves the cursor to the next reply to the same comment, like the HN "next" link.
+func (c *commentView) nextSibling() {
+	node, ok := c.current()
+	if !ok {
+		return
+	}
+
+	siblings := c.comments
+	if node.Parent != nil {
+		siblings = node.Parent.Children
+	}
+
+	after := false
+
+	for _, sibling := range siblings {
+		if i, ok := c.indexOf(sibling); ok && after {
+			c.moveTo(i)
+
+			return
+		}
+
+		after = after || sibling == node
+	}
+}
+
+// toggleFold folds or unfolds the replies of the comment under the cursor.
+func (c *commentView) toggleFold() {
+	node, ok := c.current()
+	if !ok || len(node.Children) == 0 {
+		return
+	}
+
+	c.folded[node.ID] = !c.folded[node.ID]
+	delete(c.blocks, node.ID)
+	c.layout(node)
+	c.model.SetYOffset(c.offsets[c.selected])
+}
+
+// foldAll leaves only the top-level comments, the cursor goes to its top-level comment.
+func (c *commentView) foldAll() {
+	node, ok := c.current()
+	if !ok {
+		return
+	}
+
+	for _, root := range c.comments {
+		if len(root.Children) > 0 {
+			c.folded[root.ID] = true
+			delete(c.blocks, root.ID)
+		}
+	}
+
+	c.layout(rootOf(node))
+	c.model.SetYOffset(c.offsets[c.selected])
+}
+
+func (c *commentView) unfoldAll() {
+	node, ok := c.current()
+	if !ok {
+		return
+	}
+
+	for id := range c.folded {
+		delete(c.blocks, id)
+	}
+
+	c.folded = map[int]bool{}
+	c.layout(node)
+	c.model.SetYOffset(c.offsets[c.selected])
+}
+
+// followScroll puts the cursor on the comment at the top of the viewport after a line scroll.
+func (c *commentView) followScroll() {
+	selected := 0
+
+	for i, offset := range c.offsets {
+		if offset > c.model.YOffset {
+			break
+		}
+
+		selected = i
+	}
+
+	if selected != c.selected {
+		c.layout(c.visible[selected])
+	}
+}
+
+func (c *commentView) current() (*hackernews.CommentNode, bool) {
+	if c.selected < 0 || c.selected >= len(c.visible) {
+		return nil, false
+	}
+
+	return c.visible[c.selected], true
+}
+
+// indexOf fails for a folded or deleted comment, as it is not visible.
+func (c *commentView) indexOf(node *hackernews.CommentNode) (int, bool) {
+	for i, visible := range c.visible {
+		if visible == node {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (c *commentView) moveTo(selected int) {
+	if len(c.visible) == 0 {
+		return
+	}
+
+	c.layout(c.visible[min(max(selected, 0), len(c.visible)-1)])
+	c.model.SetYOffset(c.offsets[c.selected])
+}
+
+// layout renders the visible comments, and marks the cursor on anchor, or on the first comment when nil.
+func (c *commentView) layout(anchor *hackernews.CommentNode) {
+	var content strings.Builder
+
+	c.visible = []*hackernews.CommentNode{}
+	c.offsets = []int{}
+	c.selected = 0
+
+	content.WriteString(c.header)
+	line := strings.Count(c.header, "\n")
+
+	var walk func(nodes []*hackernews.CommentNode)
+
+	walk = func(nodes []*hackernews.CommentNode) {
+		for _, node := range nodes {
+			block, ok := c.block(node)
+			if ok {
+				marker := " "
+				if node == anchor || (anchor == nil && len(c.visible) == 0) {
+					marker = c.cursor.Render("▌")
+					c.selected = len(c.visible)
+				}
+
+				c.visible = append(c.visible, node)
+				c.offsets = append(c.offsets, line)
+
+				for _, blockLine := range strings.Split(block, "\n") {
+					content.WriteString(marker + blockLine + "\n")
+					line++
+				}
+			}
+
+			if !c.folded[node.ID] {
+				walk(node.Children)
+			}
+		}
+	}
+
+	walk(c.comments)
+
+	c.model.SetContent(content.String())
+}
+
+func (c *commentView) block(node *hackernews.CommentNode) (string, bool) {
+	if block, ok := c.blocks[node.ID]; ok {
+		return block, true
+	}
+
+	comment := c.formatComment(node)
+	if comment == "" {
+		return "", false
+	}
+
+	block := strings.TrimRight(c.render(comment), "\n")
+	c.blocks[node.ID] = block
+
+	return block, true
+}
+
+func (c *commentView) render(markdown string) string {
+	if c.renderer == nil {
+		return markdown
+	}
+
+	render, err := c.renderer.Render(markdown)
+	if err != nil {
+		return markdown
+	}
+
+	return render
+}
+
+func (c *commentView) formatComment(node *hackernews.CommentNode) string {
+	const gap = "\n\n"
+
+	text := node.Text
+	author := node.By + " | " + node.TimeAgo
+
+	switch {
+	case node.Failed:
+		text = "*[failed to load]*"
+		author = fmt.Sprintf("#%d", node.ID) // Only the ID is known
+	case node.Deleted:
+		text = "*[deleted]*"
+	case node.Dead:
+		text = "*[dead]* " + text
+	}
+
+	// A deleted comment without replies is not worth a line
+	if text == "" || (node.Deleted && len(node.Children) == 0) {
+		return ""
+	}
+
+	blockquotes := strings.Repeat(">", node.Depth+1)
+	separator := ""
+	header := ""
+	replies := ""
+
+	if node.Depth == 0 {
+		separator = "---" + gap
+		header = "# "
+	}
+
+	if c.since > 0 && node.Time > c.since && !node.Failed {
+		author += " | ✨ **new**"
+	}
+
+	if c.folded[node.ID] {
+		count := countReplies(node)
+		replies = fmt.Sprintf(" [+%d %s]", count, plural(count, "reply", "replies"))
+	}
+	// Remove duplicated links created by htmltomarkdown lib
+	text = c.linkRegexp.ReplaceAllString(text, "[link]($1)")
+
+	return fmt.Sprintf(separator+blockquotes+header+"%s%s %s",
+		author,
+		replies,
+		strings.ReplaceAll(gap+text, "\n", "\n"+blockquotes))
+}
+
+func (c *commentView) updateWindow(width int, height int) {
+	margin := lipgloss.Height(c.headerView()) + lipgloss.Height(c.footerView())
+	c.model.Width = width
+	c.model.Height = height - margin
+}
+
+// formatDiscussion lists comments for the model, each with its author and indented under its parent,
+// a thread per paragraph. Comments that failed, were deleted or are dead are left out, not their replies.
+func formatDiscussion(comments []*hackernews.CommentNode) string {
+	threads := []string{}
+
+	var format func(lines []string, node *hackernews.CommentNode) []string
+
+	format = func(lines []string, node *hackernews.CommentNode) []string {
+		if !node.Failed && !node.Deleted && !node.Dead && node.Text != "" {
+			text := strings.Join(strings.Fields(node.Text), " ")
+			lines = append(lines, fmt.Sprintf("%s- %s: %s", strings.Repeat("  ", node.Depth), node.By, text))
+		}
+
+		for _, child := range node.Children {
+			lines = format(lines, child)
+		}
+
+		return lines
+	}
+
+	for _, comment := range comments {
+		if lines := format([]string{}, comment); len(lines) > 0 {
+			threads = append(threads, strings.Join(lines, "\n"))
+		}
+	}
+
+	return strings.Join(threads, "\n\n")
+}
+
+func countReplies(node *hackernews.CommentNode) int {
+	count := len(node.Children)
+
+	for _, child := range node.Children {
+		count += countReplies(child)
+	}
+
+	return count
+}
+
+func rootOf(node *hackernews.CommentNode) *hackernews.CommentNode {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+
+	return node
+}
+
+func plural(count int, one string, many string) string {
+	if count == 1 {
+		return one
+	}
+
+	return many
+}
+
+// readComment batches the comments already fetched, so that the layout is not redone for each of them.
+func readComment(stream <-chan *hackernews.CommentNode) tea.Cmd {
+	const batch = 50
+
+	return func() tea.Msg {
+		node, ok := <-stream
+		if !ok {
+			return commentMsg{stream: stream, nodes: []*hackernews.CommentNode{}, done: true}
+		}
+
+		nodes := []*hackernews.CommentNode{node}
+
+		for len(nodes) < batch {
+			select {
+			case node, ok := <-stream:
+				if !ok {
+					return commentMsg{stream: stream, nodes: nodes, done: true}
+				}
+
+				nodes = append(nodes, node)
+			default:
+				return commentMsg{stream: stream, nodes: nodes, done: false}
+			}
+		}
+
+		return commentMsg{stream: stream, nodes: nodes, done: false}
+	}
+}
@@ -0,0 +1,222 @@
+package bubbleterm
+
+import (
+	"chamot/cmd/hackernews"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newCommentNode(id int, by string, parent *hackernews.CommentNode, deleted bool) *hackernews.CommentNode {
+	depth := 0
+	if parent != nil {
+		depth = parent.Depth + 1
+	}
+
+	node := &hackernews.CommentNode{
+		ID:       id,
+		By:       by,
+		Text:     "Comment from " + by,
+		Time:     1741746022,
+		TimeAgo:  "x days ago",
+		Depth:    depth,
+		Dead:     false,
+		Deleted:  deleted,
+		Failed:   false,
+		Kids:     []int{},
+		Parent:   parent,
+		Children: []*hackernews.CommentNode{},
+	}
+
+	if parent != nil {
+		parent.Kids = append(parent.Kids, id)
+		parent.Children = append(parent.Children, node)
+	}
+
+	return node
+}
+
+func TestCommentJump(t *testing.T) {
+	a := newCommentNode(1, "a", nil, false)
+	a1 := newCommentNode(2, "a1", a, false)
+	newCommentNode(3, "a1a", a1, false)
+	newCommentNode(4, "a2", a, false)
+
+	b := newCommentNode(5, "b", nil, false)
+	newCommentNode(6, "b1", b, true)
+	newCommentNode(7, "b2", b, false)
+
+	c := newCommentNode(8, "c", nil, false)
+
+	view := newCommentView(lipgloss.NewStyle())
+	view.setContent(hackernews.Story{}, []*hackernews.CommentNode{a, b, c}) //nolint:exhaustruct // Not used
+
+	tests := []struct {
+		name       string
+		action     func()
+		expectedBy string
+	}{
+		{name: "Cursor starts on the first comment", action: func() {}, expectedBy: "a"},
+		{name: "] jumps to the next top-level comment", action: view.nextRoot, expectedBy: "b"},
+		{name: "J skips the deleted reply", action: view.next, expectedBy: "b2"},
+		{name: "n stays on the last reply", action: view.nextSibling, expectedBy: "b2"},
+		{name: "[ jumps to the top-level comment of the thread", action: view.previousRoot, expectedBy: "b"},
+		{name: "[ jumps to the previous top-level comment", action: view.previousRoot, expectedBy: "a"},
+		{name: "J moves to the first reply", action: view.next, expectedBy: "a1"},
+		{name: "n jumps over the replies of the reply", action: view.nextSibling, expectedBy: "a2"},
+		{name: "p jumps to the parent", action: view.parent, expectedBy: "a"},
+		{name: "p stays on a top-level comment", action: view.parent, expectedBy: "a"},
+		{name: "z folds the thread so J skips it", action: func() { view.toggleFold(); view.next() }, expectedBy: "b"},
+		{name: "U unfolds and keeps the cursor", action: view.unfoldAll, expectedBy: "b"},
+		{name: "Z from a reply goes to the top-level comment", action: func() { view.next(); view.foldAll() }, expectedBy: "b"},
+		{name: "] jumps to the last top-level comment", action: view.nextRoot, expectedBy: "c"},
+		{name: "] stays on the last top-level comment", action: view.nextRoot, expectedBy: "c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.action()
+
+			node, ok := view.current()
+			if !ok {
+				t.Fatalf("Expected a comment under the cursor")
+			}
+
+			if node.By != tt.expectedBy {
+				t.Errorf("Expected cursor on '%s', got '%s'", tt.expectedBy, node.By)
+			}
+		})
+	}
+}
+
+func TestCommentStream(t *testing.T) {
+	first := newCommentNode(1, "a", nil, false)
+	second := newCommentNode(2, "b", nil, false)
+	story := hackernews.Story{Kids: []int{1, 2}, NumComment: 2} //nolint:exhaustruct // Not used
+
+	view := newCommentView(lipgloss.NewStyle())
+	view.updateWindow(80, 40)
+	view.start(context.Background(), story, 0, make(chan *hackernews.CommentNode))
+
+	tests := []struct {
+		name                   string
+		action                 func()
+		expectedFooterContains string
+		expectedFirst          string
+	}{
+		{
+			name:                   "Second comment comes first",
+			action:                 func() { view.add([]*hackernews.CommentNode{second}) },
+			expectedFooterContains: "1/2",
+			expectedFirst:          "b",
+		},
+		{
+			name:                   "First comment is put before",
+			action:                 func() { view.add([]*hackernews.CommentNode{first}) },
+			expectedFooterContains: "2/2",
+			expectedFirst:          "a",
+		},
+		{
+			name:                   "Progress is gone once done",
+			action:                 view.finish,
+			expectedFooterContains: "100%",
+			expectedFirst:          "a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.action()
+
+			if footer := view.footerView(); !strings.Contains(footer, tt.expectedFooterContains) {
+				t.Errorf("Expected footer to contain '%s', got %v", tt.expectedFooterContains, footer)
+			}
+
+			if view.visible[0].By != tt.expectedFirst {
+				t.Errorf("Expected first comment from '%s', got '%s'", tt.expectedFirst, view.visible[0].By)
+			}
+		})
+	}
+}
+
+func TestFormatComment(t *testing.T) {
+	view := newCommentView(lipgloss.NewStyle())
+	root := newCommentNode(1, "pg", nil, false)
+	deleted := newCommentNode(2, "", root, true)
+	failed := newCommentNode(3, "", root, false)
+	failed.Failed = true
+	failed.Text = ""
+
+	tests := []struct {
+		name             string
+		node             *hackernews.CommentNode
+		since            int64
+		expectedContains string
+	}{
+		{"comment", root, 0, "pg | x days ago"},
+		{"deleted without replies", deleted, 0, ""},
+		{"failed", failed, 0, "*[failed to load]*"},
+		{"failed shows its ID", failed, 0, "#3"},
+		{"posted since the last visit", root, root.Time - 1, "✨"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view.since = tt.since
+			got := view.formatComment(tt.node)
+
+			if tt.expectedContains == "" && got != "" {
+				t.Errorf("formatComment() = %q; want nothing", got)
+			}
+
+			if !strings.Contains(got, tt.expectedContains) {
+				t.Errorf("formatComment() = %q; want it to contain %q", got, tt.expectedContains)
+			}
+
+			if tt.since == 0 && strings.Contains(got, "✨") {
+				t.Errorf("formatComment() = %q; want no new comment without a previous visit", got)
+			}
+		})
+	}
+}
+
+func TestFormatDiscussion(t *testing.T) {
+	root := newCommentNode(1, "pg", nil, false)
+	reply := newCommentNode(2, "dang", root, false)
+	reply.Text = "Comment\nover two lines"
+	deleted := newCommentNode(3, "", root, true)
+	newCommentNode(4, "tptacek", deleted, false)
+	lonely := newCommentNode(5, "", nil, true)
+
+	tests := []struct {
+		name     string
+		comments []*hackernews.CommentNode
+		expected string
+	}{
+		{
+			name:     "no comment",
+			comments: []*hackernews.CommentNode{},
+			expected: "",
+		},
+		{
+			name:     "replies indented under their parent",
+			comments: []*hackernews.CommentNode{root},
+			expected: "- pg: Comment from pg\n  - dang: Comment over two lines\n    - tptacek: Comment from tptacek",
+		},
+		{
+			name:     "a thread per paragraph, empty ones left out",
+			comments: []*hackernews.CommentNode{reply, lonely, newCommentNode(6, "sama", nil, false)},
+			expected: "  - dang: Comment over two lines\n\n- sama: Comment from sama",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDiscussion(tt.comments); got != tt.expected {
+				t.Errorf("formatDiscussion() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package bubbleterm
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// linkView lists the links of an article or of comments, to read one of them or open it in the browser.
+type linkView struct {
+	style      lipgloss.Style
+	model      list.Model
+	back       int            // The view the links were picked from
+	linkRegexp *regexp.Regexp // [text](url "title"), kept here to avoid recompiling each time
+}
+
+type linkItem struct {
+	rank int
+	text string
+	url  string
+}
+
+func newLinkView(style lipgloss.Style) *linkView {
+	model := newStoryList(style, []list.Item{})
+	model.SetStatusBarItemName("link", "links")
+
+	return &linkView{
+		style:      lipgloss.NewStyle().Margin(1, 2),
+		model:      model,
+		back:       articleState,
+		linkRegexp: regexp.MustCompile(`\[((?:[^\[\]]|\[[^\]]*\])*)\]\((\S+?)(?:\s+"[^"]*")?\)`),
+	}
+}
+
+func (l *linkView) view() string {
+	return l.style.Render(l.model.View())
+}
+
+// setLinks lists links, picked from the back view and found in title.
+func (l *linkView) setLinks(back int, title string, links []linkItem) tea.Cmd {
+	l.back = back
+	l.model.Title = "🔗 Links | " + title
+	l.model.ResetSelected()
+
+	items := []list.Item{}
+	for _, link := range links {
+		items = append(items, link)
+	}
+
+	return l.model.SetItems(items)
+}
+
+func (l *linkView) selected() (linkItem, bool) {
+	item, ok := l.model.SelectedItem().(linkItem)
+
+	return item, ok
+}
+
+// selectRank moves the cursor to the link numbered key, if it is a number listed.
+func (l *linkView) selectRank(key string) bool {
+	rank, err := strconv.Atoi(key)
+	if err != nil || rank < 1 || rank > len(l.model.Items()) {
+		return false
+	}
+
+	l.model.Select(rank - 1)
+
+	return true
+}
+
+func (l *linkView) updateWindow(width int, height int) {
+	x, y := l.style.GetFrameSize()
+	l.model.SetSize(width-x, height-y)
+}
+
+// find appends to links those of markdown in order, once each. Relative links are resolved against
+// base, those that cannot be, or that do not point to a web page, are left out.
+func (l *linkView) find(markdown string, base string, links []linkItem) []linkItem {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		baseURL = &url.URL{} //nolint:exhaustruct // An empty URL resolves nothing
+	}
+
+	for _, match := range l.linkRegexp.FindAllStringSubmatch(markdown, -1) {
+		text, href := match[1], match[2]
+
+		ref, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+
+		resolved := baseURL.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			continue
+		}
+
+		if slices.ContainsFunc(links, func(link linkItem) bool { return link.url == resolved.String() }) {
+			continue
+		}
+
+		if text == "" {
+			text = resolved.String()
+		}
+
+		links = append(links, linkItem{rank: len(links) + 1, text: text, url: resolved.String()})
+	}
+
+	return links
+}
+
+func (i linkItem) Title() string {
+	return fmt.Sprintf("%d. %s", i.rank, i.text)
+}
+
+func (i linkItem) Description() string { return i.url }
+
+func (i linkItem) FilterValue() string { return i.text }
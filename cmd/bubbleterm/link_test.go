@@ -0,0 +1,39 @@
+package bubbleterm
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFindLinks(t *testing.T) {
+	view := newLinkView(lipgloss.NewStyle())
+
+	tests := []struct {
+		name     string
+		markdown string
+		base     string
+		expected []string
+	}{
+		{"absolute", "See [YC](https://yc.com).", "", []string{"https://yc.com"}},
+		{"relative to the article", "[Blog](/blog)", "https://yc.com/about", []string{"https://yc.com/blog"}},
+		{"title left out", `[YC](https://yc.com "Y Combinator")`, "", []string{"https://yc.com"}},
+		{"image in the text", "[![logo](/logo.png)](https://yc.com)", "", []string{"https://yc.com"}},
+		{"once each", "[YC](https://yc.com) and [again](https://yc.com)", "", []string{"https://yc.com"}},
+		{"not a web page", "[mail](mailto:pg@ycombinator.com) [anchor](#top)", "", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls := []string{}
+			for _, link := range view.find(tt.markdown, tt.base, []linkItem{}) {
+				urls = append(urls, link.url)
+			}
+
+			if !slices.Equal(urls, tt.expected) {
+				t.Errorf("find() = %v; want %v", urls, tt.expected)
+			}
+		})
+	}
+}
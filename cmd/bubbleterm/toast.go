@@ -0,0 +1,67 @@
+package bubbleterm
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// toast shows the last error over the bottom of the current view, until it is
+// dismissed or retried.
+type toast struct {
+	style lipgloss.Style
+	width int
+	err   error
+	retry func() tea.Cmd
+}
+
+func newToast(style lipgloss.Style) *toast {
+	return &toast{
+		style: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(style.GetForeground()).
+			Padding(0, 1),
+		width: 0,
+		err:   nil,
+		retry: nil,
+	}
+}
+
+func (t *toast) show(err error, retry func() tea.Cmd) {
+	t.err = err
+	t.retry = retry
+}
+
+func (t *toast) active() bool {
+	return t.err != nil
+}
+
+func (t *toast) dismiss() {
+	t.err = nil
+	t.retry = nil
+}
+
+// view replaces the last lines of content with the toast, the view keeps its height.
+func (t *toast) view(content string) string {
+	if !t.active() {
+		return content
+	}
+
+	help := "esc dismiss"
+	if t.retry != nil {
+		help = "ctrl+r retry · " + help
+	}
+
+	box := t.style.Width(max(0, t.width-t.style.GetHorizontalFrameSize())).
+		Render("⚠ " + t.err.Error() + "\n" + help)
+
+	lines := strings.Split(content, "\n")
+	lines = lines[:max(0, len(lines)-lipgloss.Height(box))]
+
+	return strings.Join(append(lines, box), "\n")
+}
+
+func (t *toast) updateWindow(width int, _ int) {
+	t.width = width
+}
@@ -0,0 +1,165 @@
+package bubbleterm
+
+import (
+	"chamot/cmd/hackernews"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// userView shows a profile above the list of its recent submissions.
+type userView struct {
+	style   lipgloss.Style
+	model   list.Model
+	spinner spinner.Model
+	profile string // Rendered markdown
+	userID  string
+	loading bool
+	request int                // Tells the user being loaded from one abandoned
+	cancel  context.CancelFunc // Stops the user being loaded
+	back    int                // The list of stories the profile was opened from
+	width   int
+	height  int
+}
+
+// submissionItem is a story or a comment of a user, as listed.
+type submissionItem struct {
+	hackernews.Submission
+}
+
+func newUserView(style lipgloss.Style) *userView {
+	model := newStoryList(style, []list.Item{})
+	model.SetShowTitle(false)
+	model.SetStatusBarItemName("submission", "submissions")
+
+	return &userView{
+		style:   lipgloss.NewStyle().Margin(1, 2),
+		model:   model,
+		spinner: newSpinner(style),
+		profile: "",
+		userID:  "",
+		loading: false,
+		request: 0,
+		cancel:  func() {},
+		back:    storyState,
+		width:   0,
+		height:  0,
+	}
+}
+
+func (u *userView) view() string {
+	if u.loading {
+		loading := fmt.Sprintf("%s Loading %s... (ctrl+x to abandon)", u.spinner.View(), u.userID)
+
+		return u.style.Render(lipgloss.NewStyle().Height(u.height).Render(loading))
+	}
+
+	return u.style.Render(u.profile + "\n" + u.model.View())
+}
+
+// selected tells the story of the submission under the cursor, if the user submitted any.
+func (u *userView) selected() (hackernews.Story, bool) {
+	item, ok := u.model.SelectedItem().(submissionItem)
+
+	return item.Story, ok
+}
+
+// start clears the previous user, and returns the context of the request,
+// cancelled once abandoned, along with the command animating the spinner.
+func (u *userView) start(ctx context.Context, userID string) (context.Context, int, tea.Cmd) {
+	u.abandon()
+	ctx, u.cancel = context.WithCancel(ctx)
+	u.request++
+	u.loading = true
+	u.userID = userID
+	u.profile = ""
+	u.model.ResetSelected()
+	cmd := u.model.SetItems([]list.Item{})
+
+	return ctx, u.request, tea.Batch(cmd, u.spinner.Tick)
+}
+
+func (u *userView) abandon() {
+	u.request++
+	u.loading = false
+	u.cancel()
+}
+
+func (u *userView) tick(msg spinner.TickMsg) tea.Cmd {
+	if !u.loading {
+		return nil
+	}
+
+	var cmd tea.Cmd
+
+	u.spinner, cmd = u.spinner.Update(msg)
+
+	return cmd
+}
+
+func (u *userView) setUser(user hackernews.User) tea.Cmd {
+	// Long abouts are cut, the submissions need the room
+	const maxAboutLines = 8
+
+	u.loading = false
+
+	about := strings.Split(strings.TrimSpace(user.About), "\n")
+	if len(about) > maxAboutLines {
+		about = append(about[:maxAboutLines], "…")
+	}
+
+	profile := fmt.Sprintf("# 👤 %s\n\n%d karma | joined %s\n\n%s", user.ID, user.Karma, user.CreatedAgo,
+		strings.Join(about, "\n"))
+
+	render, err := glamour.RenderWithEnvironmentConfig(profile)
+	if err != nil {
+		render = profile
+	}
+
+	u.profile = render
+	u.resize()
+
+	items := []list.Item{}
+	for _, submission := range user.Submissions {
+		items = append(items, submissionItem{Submission: submission})
+	}
+
+	return u.model.SetItems(items)
+}
+
+func (u *userView) updateWindow(width int, height int) {
+	u.width = width
+	u.height = height
+	u.resize()
+}
+
+func (u *userView) resize() {
+	x, y := u.style.GetFrameSize()
+	u.model.SetSize(u.width-x, u.height-y-lipgloss.Height(u.profile))
+}
+
+func (i submissionItem) Title() string {
+	if i.Comment != nil {
+		return fmt.Sprintf("%d. 💬 on %s", i.Story.Rank+1, i.Story.PostTitle)
+	}
+
+	return i.Story.Title()
+}
+
+func (i submissionItem) Description() string {
+	if i.Comment != nil {
+		text, _, _ := strings.Cut(strings.TrimSpace(i.Comment.Text), "\n")
+
+		return i.Comment.TimeAgo + " | " + text
+	}
+
+	return i.Story.Description()
+}
+
+func (i submissionItem) FilterValue() string { return i.Story.PostTitle }
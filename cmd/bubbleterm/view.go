@@ -2,13 +2,19 @@ package bubbleterm
 
 import (
 	"chamot/cmd/hackernews"
+	"chamot/cmd/library"
 	"chamot/cmd/ollama"
+	"context"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
@@ -16,59 +22,133 @@ import (
 )
 
 type storyView struct {
-	style lipgloss.Style
-	model list.Model
-}
-
-type commentView struct {
-	style lipgloss.Style
-	model viewport.Model
+	style   lipgloss.Style
+	model   list.Model
+	search  textinput.Model
+	query   string
+	page    int
+	loading bool
+	done    bool
+	width   int
+	height  int
+	watch   <-chan hackernews.StoryUpdate
+	cancel  context.CancelFunc // Stops watching the feed
+	ranks   map[int]int        // Live rank of each story ID of the feed
+	opened  map[int]bool       // Story IDs of the feed when it was first watched
+	library *library.Library
+}
+
+// storyItem is a story as listed, along with what only the app knows about it.
+type storyItem struct {
+	hackernews.Story
+	fresh       bool // Entered the feed after it was opened
+	read        bool
+	newComments int // Since the story was last read
+	bookmarked  bool
+}
+
+// storyDelegate dims the stories already read.
+type storyDelegate struct {
+	list.DefaultDelegate
+	read list.DefaultDelegate
 }
 
 type articleView struct {
-	style lipgloss.Style
-	model viewport.Model
-}
+	style   lipgloss.Style
+	model   viewport.Model
+	spinner spinner.Model
+	loading bool
+	request int                // Tells the article being loaded from one abandoned
+	cancel  context.CancelFunc // Stops the article being loaded
+	story   hackernews.Story
+	content string // Markdown, before rendering
+	back    int    // The view the article was opened from
+}
+
+const (
+	// chatSystemPrompt opens every conversation with Ollama.
+	chatSystemPrompt = "You are Cha(t)mot, the assistant of a Hacker News terminal app. " +
+		"Answer briefly, in markdown, and keep in mind what was said earlier in the conversation."
+	// storyPrompt grounds a conversation on a story, its article and comments follow.
+	storyPrompt = "The user is reading the Hacker News story %q. Answer their questions from its article " +
+		"and its comments given below, quote who said what when it matters, and tell when they do not say."
+	chatGreeting = "Hi, I'm 🐈 Cha(t)mot. What can I help with?"
+)
 
 type chatView struct {
 	model     viewport.Model
 	messages  []string
 	prompt    textarea.Model
 	responses []string
-	input     chan string
-	output    chan ollama.Response
-	stop      chan bool
+	history   []ollama.Message // The conversation sent to Ollama, the system prompt first
+	answer    string           // The response being streamed, added to history once done
+	grounding []ollama.Message // The story, its article and comments, sent after the system prompt with each prompt
+	story     hackernews.Story // The story the conversation is about, if any
+	back      int              // The view the chat was opened from
+	stream    <-chan ollama.Response
+	cancel    context.CancelFunc // Stops the response being streamed
+}
+
+func newStoryView(style lipgloss.Style, feed hackernews.Feed, stories []hackernews.Story,
+	library *library.Library,
+) *storyView {
+	model := newStoryList(style, storyItems(stories, nil, library))
+	model.Title = storyTitle(feed)
+
+	search := textinput.New()
+	search.Placeholder = "Search stories..."
+	search.Prompt = "🔎 "
+	search.Cursor.Style = style
+	search.CharLimit = 100
+
+	return &storyView{
+		style:   lipgloss.NewStyle().Margin(1, 2),
+		model:   model,
+		search:  search,
+		query:   "",
+		page:    0,
+		loading: false,
+		done:    false,
+		width:   0,
+		height:  0,
+		watch:   nil,
+		cancel:  func() {},
+		ranks:   nil,
+		opened:  nil,
+		library: library,
+	}
 }
 
-func newStoryView(style lipgloss.Style, items []list.Item) *storyView {
+func newStoryList(style lipgloss.Style, items []list.Item) list.Model {
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = style
 	delegate.Styles.SelectedDesc = style
 
-	model := list.New(items, delegate, 0, 0)
+	read := delegate
+	read.Styles.NormalTitle = read.Styles.DimmedTitle
+	read.Styles.NormalDesc = read.Styles.DimmedDesc
+
+	model := list.New(items, storyDelegate{DefaultDelegate: delegate, read: read}, 0, 0)
 	model.Styles.Title = lipgloss.NewStyle().Bold(true)
-	model.Title = "🐫 Chamot"
 	model.SetFilteringEnabled(false)
 	model.SetShowHelp(false)
 	model.SetShowStatusBar(false)
+	model.DisableQuitKeybindings() // Esc and q quit the whole app otherwise, Chamot quits on ctrl+c
 
-	return &storyView{
-		style: lipgloss.NewStyle().Margin(1, 2),
-		model: model,
-	}
-}
-
-func newCommentView() *commentView {
-	return &commentView{
-		style: lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).Padding(0, 1),
-		model: viewport.New(0, 0),
-	}
+	return model
 }
 
-func newArticleView() *articleView {
+func newArticleView(style lipgloss.Style) *articleView {
 	return &articleView{
-		style: lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).Padding(0, 1),
-		model: viewport.New(0, 0),
+		style:   lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).Padding(0, 1),
+		model:   viewport.New(0, 0),
+		spinner: newSpinner(style),
+		loading: false,
+		request: 0,
+		cancel:  func() {},
+		story:   hackernews.Story{}, //nolint:exhaustruct // No story yet
+		content: "",
+		back:    storyState,
 	}
 }
 
@@ -98,12 +178,16 @@ func newChatView(style lipgloss.Style) *chatView {
 
 	return &chatView{
 		model:     model,
-		messages:  []string{"Hi, I'm 🐈 Cha(t)mot. What can I help with?" + "\n\n"},
+		messages:  []string{chatGreeting + "\n\n"},
 		prompt:    prompt,
 		responses: []string{},
-		input:     make(chan string, 10),
-		output:    make(chan ollama.Response, 1),
-		stop:      make(chan bool, 1),
+		history:   []ollama.Message{{Role: ollama.RoleSystem, Content: chatSystemPrompt}},
+		answer:    "",
+		grounding: nil,
+		story:     hackernews.Story{}, //nolint:exhaustruct // No story yet
+		back:      storyState,
+		stream:    nil,
+		cancel:    func() {},
 	}
 }
 
@@ -111,51 +195,311 @@ func (s *storyView) view() string {
 	return s.style.Render(s.model.View())
 }
 
-func (s *storyView) selected() hackernews.Story {
-	return s.model.SelectedItem().(hackernews.Story)
+// selected tells the story under the cursor, if the list is not empty.
+func (s *storyView) selected() (hackernews.Story, bool) {
+	return selectedStory(s.model)
+}
+
+func (s *storyView) searchView() string {
+	return s.style.Render(fmt.Sprintf("%s\n\n%s", s.search.View(), s.model.View()))
+}
+
+func (s *storyView) focusSearch() tea.Cmd {
+	s.search.Reset()
+	cmd := s.search.Focus()
+	s.resize()
+
+	return cmd
+}
+
+func (s *storyView) blurSearch() {
+	s.search.Blur()
+	s.resize()
+}
+
+// setResults shows the stories found for query, they all fit in one page.
+func (s *storyView) setResults(query string, stories []hackernews.Story) tea.Cmd {
+	s.model.Title = "🐫 Chamot | Search: " + query
+	s.model.ResetSelected()
+	s.query = query
+	s.page = 0
+	s.loading = false
+	s.done = true
+
+	return s.model.SetItems(storyItems(stories, nil, s.library))
+}
+
+func (s *storyView) setFeed(feed hackernews.Feed, stories []hackernews.Story) tea.Cmd {
+	s.model.Title = storyTitle(feed)
+	s.model.ResetSelected()
+	s.query = ""
+	s.page = 0
+	s.loading = false
+	s.done = false
+
+	return s.model.SetItems(storyItems(stories, nil, s.library))
+}
+
+// nextPage tells which page to load once the cursor gets close to the end of the list.
+func (s *storyView) nextPage() (int, bool) {
+	const preload = 5
+
+	if s.loading || s.done || s.model.Index() < len(s.model.Items())-preload {
+		return 0, false
+	}
+
+	s.loading = true
+
+	return s.page + 1, true
+}
+
+func (s *storyView) appendPage(page int, stories []hackernews.Story) tea.Cmd {
+	s.loading = false
+	s.page = page
+
+	if len(stories) == 0 {
+		s.done = true
+
+		return nil
+	}
+
+	// The feed may have moved since the previous page
+	stories = slices.DeleteFunc(stories, func(story hackernews.Story) bool {
+		return slices.ContainsFunc(s.model.Items(), func(item list.Item) bool {
+			return item.(storyItem).ID == story.ID
+		})
+	})
+
+	return s.model.SetItems(append(s.model.Items(), storyItems(stories, s.opened, s.library)...))
+}
+
+// markRead dims the story, its new comments are all read by now if comments is set.
+func (s *storyView) markRead(storyID int, comments bool) tea.Cmd {
+	return updateStory(&s.model, storyID, func(story *storyItem) {
+		story.read = true
+
+		if comments {
+			story.newComments = 0
+		}
+	})
+}
+
+func (s *storyView) markBookmarked(storyID int, bookmarked bool) tea.Cmd {
+	return updateStory(&s.model, storyID, func(story *storyItem) {
+		story.bookmarked = bookmarked
+	})
+}
+
+// watchFeed stops watching the previous feed, and returns the context of the next one along with
+// the stream its updates are read from.
+func (s *storyView) watchFeed(ctx context.Context) (context.Context, chan hackernews.StoryUpdate) {
+	s.cancel()
+	ctx, s.cancel = context.WithCancel(ctx)
+	stream := make(chan hackernews.StoryUpdate, 1)
+	s.watch = stream
+	s.ranks = nil
+	s.opened = nil
+
+	return ctx, stream
+}
+
+func (s *storyView) stopWatch() {
+	s.watch = nil
+	s.cancel()
+}
+
+// applyUpdate follows the live feed, the stories move to their new rank and the cursor stays on its story.
+func (s *storyView) applyUpdate(update hackernews.StoryUpdate) tea.Cmd {
+	if update.IDs != nil {
+		s.ranks = make(map[int]int, len(update.IDs))
+
+		for rank, storyID := range update.IDs {
+			s.ranks[storyID] = rank
+		}
+
+		if s.opened == nil {
+			s.opened = make(map[int]bool, len(update.IDs))
+
+			for _, storyID := range update.IDs {
+				s.opened[storyID] = true
+			}
+		}
+	}
+
+	// Search results are not part of the feed
+	if s.query != "" || s.ranks == nil {
+		return nil
+	}
+
+	loaded := map[int]hackernews.Story{}
+
+	for _, item := range s.model.Items() {
+		loaded[item.(storyItem).ID] = item.(storyItem).Story
+	}
+
+	stories := []hackernews.Story{}
+
+	for _, story := range update.Stories {
+		// A story beyond the pages loaded comes with its page
+		if _, ok := loaded[story.ID]; ok || story.Rank < len(loaded) {
+			loaded[story.ID] = story
+		}
+	}
+
+	for _, story := range loaded {
+		rank, ok := s.ranks[story.ID]
+		if !ok {
+			continue // Dropped off the feed
+		}
+
+		story.Rank = rank
+		stories = append(stories, story)
+	}
+
+	slices.SortFunc(stories, func(a hackernews.Story, b hackernews.Story) int {
+		return a.Rank - b.Rank
+	})
+
+	return s.setStories(stories)
+}
+
+// refresh replaces the first page with stories, the next pages already loaded are kept.
+func (s *storyView) refresh(stories []hackernews.Story) tea.Cmd {
+	for _, item := range s.model.Items() {
+		story := item.(storyItem).Story
+		known := slices.ContainsFunc(stories, func(fresh hackernews.Story) bool { return fresh.ID == story.ID })
+
+		if !known && story.Rank >= len(stories) {
+			stories = append(stories, story)
+		}
+	}
+
+	return s.setStories(stories)
+}
+
+// setStories lists stories, the cursor stays on the same story rather than the same index.
+func (s *storyView) setStories(stories []hackernews.Story) tea.Cmd {
+	selected, ok := s.selected()
+	cmd := s.model.SetItems(storyItems(stories, s.opened, s.library))
+
+	if i := slices.IndexFunc(stories, func(story hackernews.Story) bool { return story.ID == selected.ID }); ok && i >= 0 {
+		s.model.Select(i)
+	}
+
+	return cmd
 }
 
 func (s *storyView) updateWindow(width int, height int) {
+	s.width = width
+	s.height = height
+	s.resize()
+}
+
+func (s *storyView) resize() {
 	x, y := s.style.GetFrameSize()
-	s.model.SetSize(width-x, height-y)
+
+	// Make room for the search prompt and its gap
+	if s.search.Focused() {
+		y += lipgloss.Height(s.search.View()) + 1
+	}
+
+	s.model.SetSize(s.width-x, s.height-y)
 }
 
-func (c *commentView) headerView() string {
-	return lipgloss.JoinHorizontal(lipgloss.Center, strings.Repeat(" ", max(0, c.model.Width)))
+func newSpinner(style lipgloss.Style) spinner.Model {
+	return spinner.New(
+		spinner.WithSpinner(spinner.Dot),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(style.GetForeground())))
 }
 
-func (c *commentView) footerView() string {
-	info := c.style.Render(fmt.Sprintf("%3.f%%", c.model.ScrollPercent()*100))
-	line := strings.Repeat(" ", max(0, c.model.Width-lipgloss.Width(info)))
+func storyTitle(feed hackernews.Feed) string {
+	return "🐫 Chamot | " + feed.Title()
+}
 
-	return lipgloss.JoinHorizontal(lipgloss.Center, line, info)
+// storyItems marks the stories missing from opened as fresh, unless opened is nil,
+// and those in library as read or bookmarked.
+func storyItems(stories []hackernews.Story, opened map[int]bool, library *library.Library) []list.Item {
+	items := []list.Item{}
+
+	for _, story := range stories {
+		visit, read := library.Visit(story.ID)
+		newComments := 0
+
+		if read {
+			newComments = max(0, story.NumComment-visit.NumComment)
+		}
+
+		items = append(items, storyItem{
+			Story:       story,
+			fresh:       opened != nil && !opened[story.ID],
+			read:        read,
+			newComments: newComments,
+			bookmarked:  library.Bookmarked(story.ID),
+		})
+	}
+
+	return items
 }
 
-func (c *commentView) view() string {
-	return fmt.Sprintf("%s\n%s\n%s", c.headerView(), c.model.View(), c.footerView())
+// selectedStory tells the story under the cursor of a list of storyItems, if it is not empty.
+func selectedStory(model list.Model) (hackernews.Story, bool) {
+	item, ok := model.SelectedItem().(storyItem)
+
+	return item.Story, ok
 }
 
-func (c *commentView) gotoTop() {
-	c.model.SetYOffset(0)
+// updateStory changes the item of the story in a list of storyItems, if it is listed.
+func updateStory(model *list.Model, storyID int, update func(*storyItem)) tea.Cmd {
+	for i, item := range model.Items() {
+		if story := item.(storyItem); story.ID == storyID {
+			update(&story)
+
+			return model.SetItem(i, story)
+		}
+	}
+
+	return nil
 }
 
-func (c *commentView) gotoBottom() {
-	c.model.SetYOffset(c.model.TotalLineCount())
+func (i storyItem) Description() string {
+	if i.newComments > 0 {
+		return fmt.Sprintf("%s | +%d new %s", i.Story.Description(), i.newComments,
+			plural(i.newComments, "comment", "comments"))
+	}
+
+	return i.Story.Description()
 }
 
-func (c *commentView) setContent(comment string) {
-	render, err := glamour.RenderWithEnvironmentConfig(comment)
-	if err != nil {
-		c.model.SetContent("")
+func (d storyDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if story, ok := item.(storyItem); ok && story.read {
+		d.read.Render(w, m, index, item)
+
+		return
 	}
 
-	c.model.SetContent(render)
+	d.DefaultDelegate.Render(w, m, index, item)
 }
 
-func (c *commentView) updateWindow(width int, height int) {
-	margin := lipgloss.Height(c.headerView()) + lipgloss.Height(c.footerView())
-	c.model.Width = width
-	c.model.Height = height - margin
+func (i storyItem) Title() string {
+	title := i.Story.Title()
+
+	if i.fresh {
+		title += " 🆕"
+	}
+
+	if i.bookmarked {
+		title += " 🔖"
+	}
+
+	return title
+}
+
+func readUpdate(stream <-chan hackernews.StoryUpdate) tea.Cmd {
+	return func() tea.Msg {
+		update, ok := <-stream
+
+		return watchMsg{stream: stream, update: update, done: !ok}
+	}
 }
 
 func (a *articleView) headerView() string {
@@ -172,7 +516,46 @@ func (a *articleView) footerView() string {
 }
 
 func (a *articleView) view() string {
-	return fmt.Sprintf("%s\n%s\n%s", a.headerView(), a.model.View(), a.footerView())
+	body := a.model.View()
+
+	if a.loading {
+		loading := fmt.Sprintf("  %s Loading the article... (ctrl+x to abandon)", a.spinner.View())
+		body = lipgloss.NewStyle().Width(a.model.Width).Height(a.model.Height).Render(loading)
+	}
+
+	return fmt.Sprintf("%s\n%s\n%s", a.headerView(), body, a.footerView())
+}
+
+// start clears the previous article, and returns the context of the request,
+// cancelled once abandoned, along with the command animating the spinner.
+func (a *articleView) start(ctx context.Context, story hackernews.Story) (context.Context, int, tea.Cmd) {
+	a.abandon()
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.request++
+	a.loading = true
+	a.story = story
+	a.content = ""
+	a.model.SetContent("")
+
+	return ctx, a.request, a.spinner.Tick
+}
+
+func (a *articleView) abandon() {
+	a.request++
+	a.loading = false
+	a.cancel()
+}
+
+func (a *articleView) tick(msg spinner.TickMsg) tea.Cmd {
+	if !a.loading {
+		return nil
+	}
+
+	var cmd tea.Cmd
+
+	a.spinner, cmd = a.spinner.Update(msg)
+
+	return cmd
 }
 
 func (a *articleView) gotoTop() {
@@ -184,6 +567,8 @@ func (a *articleView) gotoBottom() {
 }
 
 func (a *articleView) setContent(article string) {
+	a.loading = false
+	a.content = article
 	render, err := glamour.RenderWithEnvironmentConfig(article)
 	if err != nil {
 		a.model.SetContent("")
@@ -198,51 +583,161 @@ func (a *articleView) updateWindow(width int, height int) {
 	a.model.Height = height - margin
 }
 
+func (c *chatView) headerView() string {
+	title := "🐈 Cha(t)mot"
+	if c.story.ID != 0 {
+		title += " | " + c.story.PostTitle
+	}
+
+	return lipgloss.NewStyle().Bold(true).MaxWidth(max(1, c.model.Width)).Render(title)
+}
+
 func (c *chatView) view() string {
-	return fmt.Sprintf("%s%s%s", c.model.View(), "\n\n", c.prompt.View())
+	return fmt.Sprintf("%s%s%s%s%s", c.headerView(), "\n\n", c.model.View(), "\n\n", c.prompt.View())
 }
 
-func (c *chatView) stopChat() {
-	// Don't block the main loop
-	select {
-	case c.stop <- true:
-	default:
+// scope starts a new conversation about story, unless it is already about it, and tells if it did.
+// The article and the comments are attached once read.
+func (c *chatView) scope(story hackernews.Story) bool {
+	const gap = "\n\n"
+
+	if story.ID == c.story.ID {
+		return false
 	}
+
+	c.stopChat()
+	c.story = story
+	c.history = []ollama.Message{{Role: ollama.RoleSystem, Content: chatSystemPrompt}}
+	c.grounding = nil
+	c.messages = []string{}
+	c.showMessage(chatGreeting + gap + "*Reading the article and the comments...⏳*" + gap)
+
+	return true
 }
-func (c *chatView) sendPrompt() {
-	// Don't block the main loop
-	select {
-	case c.input <- c.prompt.Value():
-	default:
+
+// setContext grounds the prompts that follow on the article and the comments of the story. The summaries
+// are not, their text is attached to them already.
+func (c *chatView) setContext(article string, discussion string) {
+	const gap = "\n\n"
+
+	attached := []ollama.Message{{Role: ollama.RoleSystem, Content: fmt.Sprintf(storyPrompt, c.story.PostTitle)}}
+
+	if article != "" {
+		attached = append(attached, ollama.Message{Role: ollama.RoleSystem, Content: "Article:" + gap + article})
+	}
+
+	if discussion != "" {
+		attached = append(attached, ollama.Message{
+			Role:    ollama.RoleSystem,
+			Content: "Comments, each with its author, replies indented under their parent:" + gap + discussion,
+		})
+	}
+
+	c.grounding = attached
+
+	// Not in the middle of a response
+	if c.stream == nil {
+		c.showMessage("*Article and comments read, ask away.*" + gap)
 	}
+}
+
+// start stops the previous response, and returns the context of the next one along with
+// the stream it is read from.
+func (c *chatView) start(ctx context.Context) (context.Context, chan ollama.Response) {
+	c.cancel()
+	ctx, c.cancel = context.WithCancel(ctx)
+	stream := make(chan ollama.Response, 1)
+	c.stream = stream
+
+	return ctx, stream
+}
+
+func (c *chatView) finish() {
+	c.stream = nil
+	c.cancel()
+}
+
+// stopChat ends the response being streamed as if it was done.
+func (c *chatView) stopChat() {
+	if c.stream == nil {
+		return
+	}
+
+	c.finish()
+	c.formatResponse(ollama.Response{Message: ollama.Message{Role: ollama.RoleAssistant, Content: ""}, Done: true}, true)
+}
+
+// sendPrompt clears the prompt, adds what was typed to the conversation, and returns the conversation
+// grounded on the story, if any. The response being streamed, if any, is stopped and kept as it is.
+func (c *chatView) sendPrompt() []ollama.Message {
+	const gap = "\n\n"
+
+	c.stopChat()
+
+	prompt := c.prompt.Value()
 	c.prompt.Reset()
-	c.model.GotoBottom()
+	c.history = append(c.history, ollama.Message{Role: ollama.RoleUser, Content: prompt})
+	c.showMessage("> " + prompt + gap)
+
+	return slices.Insert(slices.Clone(c.history), 1, c.grounding...)
 }
 
-func (c *chatView) sendArticle(article string) {
-	article = "summarize this article in 10 lines" + article
+// sendArticle adds the request for the summary of an article to the conversation, and returns
+// the conversation. The article itself is attached to the request when sent, not kept in the history.
+func (c *chatView) sendArticle() []ollama.Message {
+	const gap = "\n\n"
 
-	// Don't block the main loop
-	select {
-	case c.input <- article:
-		const gap = "\n\n"
-		c.messages = append(c.messages, gap+"*Summarizing the article in progress...⏳*"+gap)
-		render, err := glamour.RenderWithEnvironmentConfig(strings.Join(c.messages, ""))
+	c.stopChat()
 
-		if err != nil {
-			c.model.SetContent("")
-		}
+	c.history = append(c.history, ollama.Message{Role: ollama.RoleUser, Content: "summarize this article in 10 lines"})
+	c.showMessage(gap + "*Summarizing the article in progress...⏳*" + gap)
 
-		c.model.SetContent(render)
-		c.model.GotoBottom()
-	default:
+	return slices.Clone(c.history)
+}
+
+// sendDiscussion adds the request for the summary of the comments on story to the conversation, and returns
+// the conversation. The comments are attached to the request when sent, not kept in the history.
+func (c *chatView) sendDiscussion(story hackernews.Story) []ollama.Message {
+	const gap = "\n\n"
+
+	c.stopChat()
+
+	c.history = append(c.history, ollama.Message{
+		Role: ollama.RoleUser,
+		Content: fmt.Sprintf("summarize the Hacker News discussion of %q in 10 lines: "+
+			"its main viewpoints, where commenters disagree, and the notable replies. "+
+			"Each comment comes with its author, replies are indented under their parent.", story.PostTitle),
+	})
+	c.showMessage(gap + "*Summarizing the discussion in progress...⏳*" + gap)
+
+	return slices.Clone(c.history)
+}
+
+// showProgress tells how many parts of a long text are summarized so far.
+func (c *chatView) showProgress(progress ollama.Progress) {
+	const gap = "\n\n"
+
+	c.showMessage(fmt.Sprintf("*Part %d of %d summarized...⏳*", progress.Done, progress.Total) + gap)
+}
+
+func (c *chatView) showMessage(message string) {
+	c.messages = append(c.messages, c.responses...)
+	c.messages = append(c.messages, message)
+	c.responses = []string{}
+	render, err := glamour.RenderWithEnvironmentConfig(strings.Join(c.messages, ""))
+
+	if err != nil {
+		c.model.SetContent("")
 	}
+
+	c.model.SetContent(render)
+	c.model.GotoBottom()
 }
 
 func (c *chatView) updateWindow(width int, height int) {
 	c.prompt.SetWidth(width)
 	c.model.Width = width
-	c.model.Height = height - c.prompt.Height() - lipgloss.Height("\n\n")
+	c.model.Height = height - c.prompt.Height() - lipgloss.Height(c.headerView()) - 2*lipgloss.Height("\n\n")
 
 	if len(c.messages) > 0 {
 		render, err := glamour.RenderWithEnvironmentConfig(strings.Join(c.messages, ""))
@@ -257,11 +752,19 @@ func (c *chatView) updateWindow(width int, height int) {
 }
 
 func (c *chatView) formatResponse(response ollama.Response, foreground bool) {
-	c.responses = append(c.responses, response.Response)
+	c.responses = append(c.responses, response.Message.Content)
+	c.answer += response.Message.Content
 
 	if response.Done {
 		const gap = "\n\n"
 		c.responses = append(c.responses, gap+"---"+gap)
+
+		// A failed response has nothing to remember, the prompt is sent again on retry
+		if c.answer != "" {
+			c.history = append(c.history, ollama.Message{Role: ollama.RoleAssistant, Content: c.answer})
+		}
+
+		c.answer = ""
 	}
 
 	if foreground {
@@ -294,3 +797,19 @@ func (c *chatView) focus() tea.Cmd {
 
 	return cmd
 }
+
+func readProgress(stream <-chan ollama.Response, updates <-chan ollama.Progress) tea.Cmd {
+	return func() tea.Msg {
+		progress, ok := <-updates
+
+		return progressMsg{stream: stream, updates: updates, progress: progress, done: !ok}
+	}
+}
+
+func readResponse(stream <-chan ollama.Response) tea.Cmd {
+	return func() tea.Msg {
+		response, ok := <-stream
+
+		return chatMsg{stream: stream, response: response, done: !ok}
+	}
+}
@@ -0,0 +1,309 @@
+package bubbleterm
+
+import (
+	"chamot/cmd/hackernews"
+	"chamot/cmd/library"
+	"chamot/cmd/ollama"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newStory(id int, rank int, score int) hackernews.Story {
+	return hackernews.Story{
+		Rank:       rank,
+		ID:         id,
+		By:         "pg",
+		PostTitle:  "Story",
+		URL:        "",
+		URLHost:    "",
+		Time:       1741702475,
+		TimeAgo:    "x days ago",
+		Kids:       []int{},
+		Score:      score,
+		NumComment: 0,
+		WebURL:     "",
+	}
+}
+
+func TestStoryApplyUpdate(t *testing.T) {
+	view := newStoryView(lipgloss.NewStyle(), hackernews.TopFeed,
+		[]hackernews.Story{newStory(1, 0, 10), newStory(2, 1, 20), newStory(3, 2, 30)}, nil)
+	view.updateWindow(80, 40)
+	view.model.Select(1) // Story 2
+
+	tests := []struct {
+		name          string
+		update        hackernews.StoryUpdate
+		expectedIDs   []int
+		expectedFresh []int
+		expectedScore int // Of the story under the cursor
+	}{
+		{
+			name:          "opened",
+			update:        hackernews.StoryUpdate{IDs: []int{1, 2, 3, 4}, Stories: []hackernews.Story{}},
+			expectedIDs:   []int{1, 2, 3},
+			expectedFresh: []int{},
+			expectedScore: 20,
+		},
+		{
+			name:          "new story on top, the cursor follows its story",
+			update:        hackernews.StoryUpdate{IDs: []int{5, 1, 2, 3, 4}, Stories: []hackernews.Story{newStory(5, 0, 50)}},
+			expectedIDs:   []int{5, 1, 2, 3},
+			expectedFresh: []int{5},
+			expectedScore: 20,
+		},
+		{
+			name:          "score changed",
+			update:        hackernews.StoryUpdate{IDs: nil, Stories: []hackernews.Story{newStory(2, 2, 21)}},
+			expectedIDs:   []int{5, 1, 2, 3},
+			expectedFresh: []int{5},
+			expectedScore: 21,
+		},
+		{
+			name:          "stories dropped off, and beyond the pages loaded",
+			update:        hackernews.StoryUpdate{IDs: []int{2, 3, 5, 4, 6}, Stories: []hackernews.Story{newStory(6, 4, 60)}},
+			expectedIDs:   []int{2, 3, 5},
+			expectedFresh: []int{5},
+			expectedScore: 21,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view.applyUpdate(tt.update)
+
+			ids := []int{}
+			fresh := []int{}
+
+			for i, item := range view.model.Items() {
+				story := item.(storyItem)
+				ids = append(ids, story.ID)
+
+				if story.Rank < i {
+					t.Errorf("story %d has rank %d at index %d", story.ID, story.Rank, i)
+				}
+
+				if story.fresh {
+					fresh = append(fresh, story.ID)
+
+					if !strings.Contains(story.Title(), "🆕") {
+						t.Errorf("story %d is not marked as new: %s", story.ID, story.Title())
+					}
+				}
+			}
+
+			if !slices.Equal(ids, tt.expectedIDs) {
+				t.Errorf("IDs = %v; want %v", ids, tt.expectedIDs)
+			}
+
+			if !slices.Equal(fresh, tt.expectedFresh) {
+				t.Errorf("fresh IDs = %v; want %v", fresh, tt.expectedFresh)
+			}
+
+			if selected, _ := view.selected(); selected.Score != tt.expectedScore {
+				t.Errorf("selected story %d has score %d; want %d", selected.ID, selected.Score, tt.expectedScore)
+			}
+		})
+	}
+}
+
+func TestStoryRefresh(t *testing.T) {
+	view := newStoryView(lipgloss.NewStyle(), hackernews.TopFeed,
+		[]hackernews.Story{newStory(1, 0, 10), newStory(2, 1, 20), newStory(3, 2, 30), newStory(4, 3, 40)}, nil)
+	view.updateWindow(80, 40)
+	view.model.Select(1) // Story 2
+
+	// The first page is two stories long, story 4 comes from the second one
+	view.refresh([]hackernews.Story{newStory(2, 0, 21), newStory(5, 1, 50)})
+
+	ids := []int{}
+	for _, item := range view.model.Items() {
+		ids = append(ids, item.(storyItem).ID)
+	}
+
+	if expected := []int{2, 5, 3, 4}; !slices.Equal(ids, expected) {
+		t.Errorf("IDs = %v; want %v", ids, expected)
+	}
+
+	if selected, _ := view.selected(); selected.ID != 2 || selected.Score != 21 {
+		t.Errorf("selected story %d with score %d; want story 2 with score 21", selected.ID, selected.Score)
+	}
+}
+
+func TestStoryRead(t *testing.T) {
+	lib, err := library.NewLibrary(filepath.Join(t.TempDir(), "library.json"))
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+
+	visited := newStory(2, 1, 20)
+	visited.NumComment = 5
+
+	if err := lib.MarkVisited(visited.ID, 3, time.Now()); err != nil {
+		t.Fatalf("MarkVisited() error = %v", err)
+	}
+
+	view := newStoryView(lipgloss.NewStyle(), hackernews.TopFeed,
+		[]hackernews.Story{newStory(1, 0, 10), visited}, lib)
+	view.updateWindow(80, 40)
+
+	tests := []struct {
+		name                string
+		action              func()
+		expectedRead        []bool
+		expectedNewComments []int
+	}{
+		{
+			name:                "visited story with comments posted since",
+			action:              func() {},
+			expectedRead:        []bool{false, true},
+			expectedNewComments: []int{0, 2},
+		},
+		{
+			name:                "article read, the new comments still to read",
+			action:              func() { view.markRead(2, false) },
+			expectedRead:        []bool{false, true},
+			expectedNewComments: []int{0, 2},
+		},
+		{
+			name:                "opened story",
+			action:              func() { view.markRead(1, true); view.markRead(2, true) },
+			expectedRead:        []bool{true, true},
+			expectedNewComments: []int{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.action()
+
+			for i, item := range view.model.Items() {
+				story := item.(storyItem)
+
+				if story.read != tt.expectedRead[i] {
+					t.Errorf("story %d read = %v; want %v", story.ID, story.read, tt.expectedRead[i])
+				}
+
+				if story.newComments != tt.expectedNewComments[i] {
+					t.Errorf("story %d new comments = %d; want %d", story.ID, story.newComments, tt.expectedNewComments[i])
+				}
+
+				if tt.expectedNewComments[i] > 0 && !strings.Contains(story.Description(), "new comments") {
+					t.Errorf("story %d does not show its new comments: %s", story.ID, story.Description())
+				}
+			}
+		})
+	}
+}
+
+func TestChatHistory(t *testing.T) {
+	view := newChatView(lipgloss.NewStyle())
+	view.updateWindow(80, 40)
+
+	send := func(prompt string) func() []ollama.Message {
+		return func() []ollama.Message {
+			view.prompt.SetValue(prompt)
+
+			return view.sendPrompt()
+		}
+	}
+
+	answer := func(content ...string) {
+		view.start(context.Background())
+
+		for i, piece := range content {
+			view.formatResponse(ollama.Response{
+				Message: ollama.Message{Role: ollama.RoleAssistant, Content: piece},
+				Done:    i == len(content)-1,
+			}, true)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		send          func() []ollama.Message
+		answer        []string
+		expectedRoles []string
+		expectedLast  string
+	}{
+		{
+			name:          "first prompt",
+			send:          send("What is Hacker News?"),
+			answer:        []string{"A news ", "site."},
+			expectedRoles: []string{ollama.RoleSystem, ollama.RoleUser},
+			expectedLast:  "What is Hacker News?",
+		},
+		{
+			name:          "follow-up prompt",
+			send:          send("Who runs it?"),
+			answer:        []string{"Y Combinator."},
+			expectedRoles: []string{ollama.RoleSystem, ollama.RoleUser, ollama.RoleAssistant, ollama.RoleUser},
+			expectedLast:  "Who runs it?",
+		},
+		{
+			name:   "article summary",
+			send:   view.sendArticle,
+			answer: nil,
+			expectedRoles: []string{
+				ollama.RoleSystem, ollama.RoleUser, ollama.RoleAssistant, ollama.RoleUser, ollama.RoleAssistant,
+				ollama.RoleUser,
+			},
+			expectedLast: "summarize this article in 10 lines",
+		},
+		{
+			name: "prompt grounded on the story",
+			send: func() []ollama.Message {
+				view.setContext("Hacker News turns twenty.", "- pg: Happy birthday.")
+
+				return send("When did it start?")()
+			},
+			answer: nil,
+			expectedRoles: []string{
+				ollama.RoleSystem, ollama.RoleSystem, ollama.RoleSystem, ollama.RoleSystem, ollama.RoleUser,
+				ollama.RoleAssistant, ollama.RoleUser, ollama.RoleAssistant, ollama.RoleUser, ollama.RoleUser,
+			},
+			expectedLast: "When did it start?",
+		},
+		{
+			name:   "summary left without the grounding",
+			send:   view.sendArticle,
+			answer: nil,
+			expectedRoles: []string{
+				ollama.RoleSystem, ollama.RoleUser, ollama.RoleAssistant, ollama.RoleUser, ollama.RoleAssistant,
+				ollama.RoleUser, ollama.RoleUser, ollama.RoleUser,
+			},
+			expectedLast: "summarize this article in 10 lines",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := tt.send()
+
+			roles := []string{}
+			for _, message := range messages {
+				roles = append(roles, message.Role)
+			}
+
+			if !slices.Equal(roles, tt.expectedRoles) {
+				t.Errorf("sent roles = %v; want %v", roles, tt.expectedRoles)
+			}
+
+			if last := messages[len(messages)-1].Content; last != tt.expectedLast {
+				t.Errorf("sent %q; want %q", last, tt.expectedLast)
+			}
+
+			answer(tt.answer...)
+		})
+	}
+
+	if answer := view.history[2].Content; answer != "A news site." {
+		t.Errorf("history kept the answer %q; want the streamed pieces joined", answer)
+	}
+}
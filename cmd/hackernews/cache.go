@@ -0,0 +1,111 @@
+package hackernews
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type kind string
+
+const (
+	feedKind    kind = "feed"
+	itemKind    kind = "item"
+	articleKind kind = "article"
+	userKind    kind = "user"
+)
+
+// Cache keeps raw HN JSON and rendered articles on disk, one file per entry.
+// A nil Cache is valid and caches nothing.
+type Cache struct {
+	dir string
+	ttl map[kind]time.Duration
+}
+
+func NewCache(dir string, feedTTL time.Duration, itemTTL time.Duration, articleTTL time.Duration) *Cache {
+	return &Cache{
+		dir: dir,
+		ttl: map[kind]time.Duration{
+			feedKind:    feedTTL,
+			itemKind:    itemTTL,
+			articleKind: articleTTL,
+			userKind:    itemTTL, // A profile changes as often as its items
+		},
+	}
+}
+
+// get returns a fresh entry, or any entry whatever its age if stale is set.
+func (c *Cache) get(k kind, key string, stale bool) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	path := c.path(k, key)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+
+	if !stale && time.Since(info.ModTime()) > c.ttl[k] {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (c *Cache) put(k kind, key string, data []byte) error {
+	if c == nil {
+		return nil
+	}
+
+	path := c.path(k, key)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("error creating cache dir: %w", err)
+	}
+
+	// Write then rename so that a concurrent reader never sees a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating cache entry: %w", err)
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing cache entry: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Cache) path(k kind, key string) string {
+	return filepath.Join(c.dir, string(k), key)
+}
+
+func articleKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return hex.EncodeToString(sum[:])
+}
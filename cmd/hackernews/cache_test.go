@@ -0,0 +1,137 @@
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	cache := NewCache(t.TempDir(), 0, time.Hour, time.Hour)
+
+	_ = cache.put(feedKind, "top", []byte("[43332658]"))
+	_ = cache.put(itemKind, "43332658", []byte(`{"by":"btilly"}`))
+
+	tests := []struct {
+		name     string
+		kind     kind
+		key      string
+		stale    bool
+		expected bool
+	}{
+		{name: "Expired feed is not served", kind: feedKind, key: "top", stale: false, expected: false},
+		{name: "Expired feed is served when stale", kind: feedKind, key: "top", stale: true, expected: true},
+		{name: "Fresh item is served", kind: itemKind, key: "43332658", stale: false, expected: true},
+		{name: "Unknown item is not served", kind: itemKind, key: "16582136", stale: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := cache.get(tt.kind, tt.key, tt.stale); got != tt.expected {
+				t.Errorf("cache.get(%s, %s, %v) = %v; want %v", tt.kind, tt.key, tt.stale, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCacheWithoutNetwork(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0/item/43335480.json" {
+			comment := Comment{
+				By:      "CSMastermind",
+				ID:      43335480,
+				Parent:  43332658,
+				Text:    "I remember the initial PG announcement about the founder",
+				Time:    1741717262,
+				Kids:    []int{},
+				Level:   0,
+				Dead:    false,
+				Deleted: false,
+			}
+			jsonData, _ := json.Marshal(comment)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	// A zero TTL forces the network first, the cache is then the fallback
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, NewCache(t.TempDir(), 0, 0, 0))
+	h.backoff = time.Millisecond // The network is gone for good, no need to wait
+
+	if _, err := h.fetchComment(context.Background(), 43335480, 0); err != nil {
+		t.Fatalf("fetchComment returned an error with network: %v", err)
+	}
+
+	if _, err := h.fetchComment(context.Background(), 16582247, 0); err == nil {
+		t.Fatalf("fetchComment cached a missing item")
+	}
+
+	mockServer.Close()
+
+	comment, err := h.fetchComment(context.Background(), 43335480, 0)
+	if err != nil {
+		t.Fatalf("fetchComment returned an error without network: %v", err)
+	}
+
+	if comment.By != "CSMastermind" {
+		t.Errorf("comment.By = %v; want %v", comment.By, "CSMastermind")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	score := atomic.Int32{}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v0/topstories.json":
+			_, _ = w.Write([]byte("[43332658]"))
+		case "/v0/item/43332658.json":
+			// The score goes up on each download
+			_, _ = fmt.Fprintf(w, `{"by":"btilly","title":"Happy 20th birthday, Y Combinator","score":%d}`, score.Add(1))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3,
+		NewCache(t.TempDir(), time.Hour, time.Hour, time.Hour))
+
+	tests := []struct {
+		name          string
+		load          func(ctx context.Context, feed Feed) ([]Story, error)
+		expectedScore int
+	}{
+		{name: "first load downloads", load: h.Story, expectedScore: 1},
+		{name: "next load is served by the fresh cache", load: h.Story, expectedScore: 1},
+		{name: "refresh downloads whatever the cache holds", load: h.Refresh, expectedScore: 2},
+		{name: "next load is served the refreshed story", load: h.Story, expectedScore: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stories, err := tt.load(context.Background(), TopFeed)
+			if err != nil {
+				t.Fatalf("load returned an error: %v", err)
+			}
+
+			if len(stories) != 1 || stories[0].Score != tt.expectedScore {
+				t.Errorf("stories = %+v; want a score of %d", stories, tt.expectedScore)
+			}
+		})
+	}
+}
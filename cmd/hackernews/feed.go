@@ -0,0 +1,68 @@
+package hackernews
+
+type Feed int
+
+const (
+	TopFeed Feed = iota
+	NewFeed
+	BestFeed
+	AskFeed
+	ShowFeed
+	JobFeed
+)
+
+// Order matters, it follows the Feed constants.
+func Feeds() []Feed {
+	return []Feed{TopFeed, NewFeed, BestFeed, AskFeed, ShowFeed, JobFeed}
+}
+
+// Name is the prefix used by the HN API, e.g. "top" for topstories.json.
+func (f Feed) Name() string {
+	switch f {
+	case TopFeed:
+		return "top"
+	case NewFeed:
+		return "new"
+	case BestFeed:
+		return "best"
+	case AskFeed:
+		return "ask"
+	case ShowFeed:
+		return "show"
+	case JobFeed:
+		return "job"
+	default:
+		return "top"
+	}
+}
+
+func (f Feed) Title() string {
+	switch f {
+	case TopFeed:
+		return "Top"
+	case NewFeed:
+		return "New"
+	case BestFeed:
+		return "Best"
+	case AskFeed:
+		return "Ask HN"
+	case ShowFeed:
+		return "Show HN"
+	case JobFeed:
+		return "Jobs"
+	default:
+		return "Top"
+	}
+}
+
+func (f Feed) Next() Feed {
+	feeds := Feeds()
+
+	return feeds[(int(f)+1)%len(feeds)]
+}
+
+func (f Feed) Previous() Feed {
+	feeds := Feeds()
+
+	return feeds[(int(f)+len(feeds)-1)%len(feeds)]
+}
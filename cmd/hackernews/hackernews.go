@@ -1,12 +1,16 @@
 package hackernews
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
@@ -14,19 +18,47 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+var (
+	errStatus    = errors.New("unexpected status")
+	errTransient = errors.New("transient failure")
+	errNotHTML   = errors.New("not an HTML document")
+)
+
+const (
+	defaultMaxConcurrency = 16
+	defaultMaxRetry       = 3
+	defaultBackoff        = 250 * time.Millisecond
+)
+
 type API interface {
-	Comment(story Story) (string, error)
-	Story() ([]Story, error)
-	Article(story Story) (string, error)
+	Comment(ctx context.Context, story Story) ([]*CommentNode, error)
+	CommentStream(ctx context.Context, story Story, out chan<- *CommentNode) error
+	Story(ctx context.Context, feed Feed) ([]Story, error)
+	Refresh(ctx context.Context, feed Feed) ([]Story, error)
+	StoryPage(ctx context.Context, feed Feed, page int) ([]Story, error)
+	Search(ctx context.Context, query string) ([]Story, error)
+	Article(ctx context.Context, story Story) (string, error)
+	Watch(ctx context.Context, feed Feed, out chan<- StoryUpdate) error
+	User(ctx context.Context, userID string) (User, error)
 }
 
 type HackerNews struct {
-	urlStory   string
-	urlItem    string
-	urlWebItem string
-	numStory   int
-	client     *http.Client
-	linkRegexp *regexp.Regexp // Keep this here to avoid recompiling each time
+	urlStory     string
+	urlItem      string
+	urlWebItem   string
+	urlSearch    string
+	urlUpdates   string
+	urlUser      string
+	numStory     int
+	cache        *Cache
+	offline      bool
+	client       *http.Client
+	streamClient *http.Client
+	storyIDs     map[Feed][]int // Keep the IDs of the first page to serve the next ones
+	mutex        sync.Mutex
+	sem          chan struct{} // Bounds the requests in flight
+	maxRetry     int
+	backoff      time.Duration // Wait before the first retry, doubled on each next one
 }
 
 type Story struct {
@@ -41,48 +73,201 @@ type Story struct {
 	Kids       []int  `json:"kids"`
 	Score      int    `json:"score"`
 	NumComment int    `json:"descendants"`
+	WebURL     string `json:"-"`
+}
+
+type searchResult struct {
+	Hits []searchHit `json:"hits"`
+}
+
+// searchHit is a story as Algolia knows it, enough to list it without asking the HN API.
+type searchHit struct {
+	ObjectID   string `json:"objectID"`
+	Author     string `json:"author"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Points     int    `json:"points"`
+	NumComment int    `json:"num_comments"` //nolint:tagliatelle // Well it's Algolia
+	Time       int    `json:"created_at_i"` //nolint:tagliatelle // Well it's Algolia
+	Kids       []int  `json:"children"`
 }
 
 type Comment struct {
-	ID     int    `json:"id"`
-	By     string `json:"by"`
-	Text   string `json:"text"`
-	Time   int64  `json:"time"`
-	Kids   []int  `json:"kids"`
-	Parent int    `json:"parent"`
-	Level  int    `json:"-"`
+	ID      int    `json:"id"`
+	By      string `json:"by"`
+	Text    string `json:"text"`
+	Time    int64  `json:"time"`
+	Kids    []int  `json:"kids"`
+	Parent  int    `json:"parent"`
+	Dead    bool   `json:"dead"`
+	Deleted bool   `json:"deleted"`
+	Level   int    `json:"-"`
+}
+
+// CommentNode is a comment placed in its thread, Text is already markdown.
+type CommentNode struct {
+	ID       int
+	By       string
+	Text     string
+	Time     int64
+	TimeAgo  string
+	Depth    int
+	Dead     bool
+	Deleted  bool
+	Failed   bool  // Could not be fetched, only ID, Depth and Parent are known
+	Kids     []int // Order of the replies on HN, Children may not have them all
+	Parent   *CommentNode
+	Children []*CommentNode
 }
 
-func NewHackerNews(urlStory string, urlItem string, urlWebItem string, numStory int) *HackerNews {
+func NewHackerNews(urlStory string, urlItem string, urlWebItem string, urlSearch string, urlUpdates string,
+	urlUser string, numStory int, cache *Cache,
+) *HackerNews {
 	return &HackerNews{
 		urlStory:   urlStory,
 		urlItem:    urlItem,
 		urlWebItem: urlWebItem,
+		urlSearch:  urlSearch,
+		urlUpdates: urlUpdates,
+		urlUser:    urlUser,
 		numStory:   numStory,
+		cache:      cache,
+		offline:    false,
 		client: &http.Client{
 			Transport:     nil,
 			CheckRedirect: nil,
 			Jar:           nil,
 			Timeout:       10 * time.Second,
 		},
-		linkRegexp: regexp.MustCompile(`\[(.*?)\]\((.*?)\)`),
+		streamClient: &http.Client{
+			Transport:     nil,
+			CheckRedirect: nil,
+			Jar:           nil,
+			Timeout:       0, // A stream never ends by itself
+		},
+		storyIDs: map[Feed][]int{},
+		mutex:    sync.Mutex{},
+		sem:      make(chan struct{}, defaultMaxConcurrency),
+		maxRetry: defaultMaxRetry,
+		backoff:  defaultBackoff,
+	}
+}
+
+// SetLimits bounds the requests to the HN API in flight, and the retries of each of them.
+// It must be called before any fetch.
+func (h *HackerNews) SetLimits(maxConcurrency int, maxRetry int) {
+	h.sem = make(chan struct{}, max(maxConcurrency, 1))
+	h.maxRetry = max(maxRetry, 0)
+}
+
+func (h *HackerNews) Story(ctx context.Context, feed Feed) ([]Story, error) {
+	return h.StoryPage(ctx, feed, 0)
+}
+
+// Refresh is Story downloading the feed and its stories again, whatever the cache holds.
+func (h *HackerNews) Refresh(ctx context.Context, feed Feed) ([]Story, error) {
+	return h.storyPage(ctx, feed, 0, true)
+}
+
+func (h *HackerNews) StoryPage(ctx context.Context, feed Feed, page int) ([]Story, error) {
+	return h.storyPage(ctx, feed, page, false)
+}
+
+// storyPage returns the stories of page of feed, fresh tells to download them whatever the cache holds.
+func (h *HackerNews) storyPage(ctx context.Context, feed Feed, page int, fresh bool) ([]Story, error) {
+	h.mutex.Lock()
+	storyIDs, ok := h.storyIDs[feed]
+	h.mutex.Unlock()
+
+	if page == 0 || !ok {
+		var err error
+
+		storyIDs, err = h.fetchStoryIDs(ctx, feed, fresh)
+		if err != nil {
+			return []Story{}, err
+		}
+
+		h.mutex.Lock()
+		h.storyIDs[feed] = storyIDs
+		h.mutex.Unlock()
 	}
+
+	start := page * h.numStory
+	if start >= len(storyIDs) {
+		return []Story{}, nil
+	}
+
+	end := min(start+h.numStory, len(storyIDs))
+
+	return h.fetchStories(ctx, storyIDs[start:end], start, fresh)
 }
 
-func (h *HackerNews) Story() ([]Story, error) {
-	storyIDs, err := h.fetchStoryIDs()
+// Search asks an Algolia HN Search API for stories, those it returns in a shape that cannot be read are left out.
+func (h *HackerNews) Search(ctx context.Context, query string) ([]Story, error) {
+	var result searchResult
+
+	if h.offline {
+		return []Story{}, fmt.Errorf("error searching story: %w", errOffline)
+	}
+
+	data, err := h.download(ctx, fmt.Sprintf(h.urlSearch, url.QueryEscape(query)))
 	if err != nil {
-		return []Story{}, err
+		return []Story{}, fmt.Errorf("error searching story: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return []Story{}, fmt.Errorf("error decoding search: %w", err)
 	}
 
-	g := errgroup.Group{}
+	stories := []Story{}
+
+	for _, hit := range result.Hits {
+		storyID, err := strconv.Atoi(hit.ObjectID)
+		if err != nil {
+			continue
+		}
+
+		story := Story{
+			Rank:       0,
+			ID:         0,
+			By:         hit.Author,
+			PostTitle:  hit.Title,
+			URL:        hit.URL,
+			URLHost:    "",
+			Time:       hit.Time,
+			TimeAgo:    "",
+			Kids:       hit.Kids,
+			Score:      hit.Points,
+			NumComment: hit.NumComment,
+			WebURL:     "",
+		}
+
+		if err := h.decorate(&story, storyID, len(stories)); err != nil {
+			continue
+		}
+
+		stories = append(stories, story)
+	}
+
+	return stories, nil
+}
+
+func (h *HackerNews) fetchStories(ctx context.Context, storyIDs []int, offset int, fresh bool) ([]Story, error) {
+	g, ctx := errgroup.WithContext(ctx)
 
 	stories := make([]Story, len(storyIDs))
 	buffer := make(chan Story, len(storyIDs))
 
-	for rank, storyID := range storyIDs {
+	for i, storyID := range storyIDs {
 		g.Go(func() error {
-			return h.fetchStory(storyID, rank, buffer)
+			story, err := h.fetchStory(ctx, storyID, offset+i, fresh)
+			if err != nil {
+				return err
+			}
+
+			buffer <- story
+
+			return nil
 		})
 	}
 
@@ -93,44 +278,63 @@ func (h *HackerNews) Story() ([]Story, error) {
 	close(buffer)
 
 	for story := range buffer {
-		stories[story.Rank] = story
+		stories[story.Rank-offset] = story
 	}
 
 	return stories, nil
 }
 
-func (h *HackerNews) Comment(story Story) (string, error) {
-	var err error
-
-	comments := map[int]Comment{}
-	buffer := make(chan Comment, story.NumComment)
+func (h *HackerNews) Comment(ctx context.Context, story Story) ([]*CommentNode, error) {
+	thread := NewThread(story)
+	buffer := make(chan *CommentNode, story.NumComment)
+	errs := make(chan error, 1)
 
 	go func() {
-		err = h.fetchComments(story.Kids, 0, buffer)
-		close(buffer)
+		errs <- h.CommentStream(ctx, story, buffer)
 	}()
 
-	if err != nil {
-		return "", fmt.Errorf("error fetching comment: %w", err)
+	for node := range buffer {
+		thread.Insert(node)
 	}
 
-	for comment := range buffer {
-		comments[comment.ID] = comment
+	if err := <-errs; err != nil {
+		return []*CommentNode{}, err
 	}
 
-	res := ""
-	for _, id := range story.Kids {
-		res += h.formatComment(comments, id)
-	}
+	return thread.Comments, nil
+}
 
-	res = fmt.Sprintf("**| 🐮 %d Co(w)mments [%s]**%s", story.NumComment, fmt.Sprintf(h.urlWebItem, story.ID), res)
+// CommentStream sends each comment as soon as it is fetched, a parent always before its replies,
+// and closes out at the end. Children are left empty, a Thread can put them back together.
+// Cancelling ctx stops the fetch, out is still closed.
+func (h *HackerNews) CommentStream(ctx context.Context, story Story, out chan<- *CommentNode) error {
+	defer close(out)
+
+	if err := h.fetchComments(ctx, story.Kids, nil, out); err != nil {
+		return fmt.Errorf("error fetching comment: %w", err)
+	}
 
-	return res, nil
+	return nil
 }
 
-func (h *HackerNews) Article(story Story) (string, error) {
-	article, err := readability.FromURL(story.URL, 10*time.Second)
+func (h *HackerNews) Article(ctx context.Context, story Story) (string, error) {
+	key := articleKey(story.URL)
+
+	if render, ok := h.cache.get(articleKind, key, h.offline); ok {
+		return string(render), nil
+	}
+
+	if h.offline {
+		return "", fmt.Errorf("error reading article: %w", errOffline)
+	}
+
+	article, err := h.readArticle(ctx, story.URL)
 	if err != nil {
+		// Better an outdated article than nothing when the network is gone
+		if render, ok := h.cache.get(articleKind, key, true); ok {
+			return string(render), nil
+		}
+
 		return "", fmt.Errorf("error reading article: %w", err)
 	}
 
@@ -139,6 +343,8 @@ func (h *HackerNews) Article(story Story) (string, error) {
 		return "", fmt.Errorf("error rendering article: %w", err)
 	}
 
+	_ = h.cache.put(articleKind, key, []byte(render)) // The article is still worth showing
+
 	return render, nil
 }
 
@@ -154,7 +360,8 @@ func (s Story) Description() string {
 
 func (s Story) FilterValue() string { return s.PostTitle }
 
-func (h *HackerNews) timeAgo(t time.Time) string {
+// TimeAgo tells how long ago t was, as HN shows it.
+func TimeAgo(t time.Time) string {
 	const hoursDay = 24
 
 	now := time.Now()
@@ -174,25 +381,29 @@ func (h *HackerNews) timeAgo(t time.Time) string {
 	}
 }
 
-func (h *HackerNews) fetchStory(storyID int, rank int, buffer chan Story) error {
+func (h *HackerNews) fetchStory(ctx context.Context, storyID int, rank int, fresh bool) (Story, error) {
 	var story Story
 
-	resp, err := h.client.Get(fmt.Sprintf(h.urlItem, storyID))
-	if err != nil {
-		return fmt.Errorf("error fetching story: %w", err)
+	fetch := h.fetch
+	if fresh {
+		fetch = h.refetch
 	}
 
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if err := json.NewDecoder(resp.Body).Decode(&story); err != nil {
-		return fmt.Errorf("error decoding story: %w", err)
+	if err := fetch(ctx, itemKind, strconv.Itoa(storyID), fmt.Sprintf(h.urlItem, storyID), &story); err != nil {
+		return story, fmt.Errorf("error fetching story: %w", err)
 	}
 
+	err := h.decorate(&story, storyID, rank)
+
+	return story, err
+}
+
+// decorate fills the fields of story that the HN API does not give.
+func (h *HackerNews) decorate(story *Story, storyID int, rank int) error {
 	story.ID = storyID
 	story.Rank = rank
-	story.TimeAgo = h.timeAgo(time.Unix(int64(story.Time), 0))
+	story.WebURL = fmt.Sprintf(h.urlWebItem, storyID)
+	story.TimeAgo = TimeAgo(time.Unix(int64(story.Time), 0))
 
 	url, err := url.Parse(story.URL)
 	if err != nil {
@@ -201,49 +412,32 @@ func (h *HackerNews) fetchStory(storyID int, rank int, buffer chan Story) error
 
 	story.URLHost = url.Host
 
-	buffer <- story
-
 	return nil
 }
 
-func (h *HackerNews) fetchStoryIDs() ([]int, error) {
+func (h *HackerNews) fetchStoryIDs(ctx context.Context, feed Feed, fresh bool) ([]int, error) {
 	var storyIDs []int
 
-	resp, err := h.client.Get(h.urlStory)
-	if err != nil {
-		return storyIDs, fmt.Errorf("error fetching story: %w", err)
-	}
-
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if err := json.NewDecoder(resp.Body).Decode(&storyIDs); err != nil {
-		return storyIDs, fmt.Errorf("error decoding story: %w", err)
+	fetch := h.fetch
+	if fresh {
+		fetch = h.refetch
 	}
 
-	if len(storyIDs) > h.numStory {
-		storyIDs = storyIDs[:h.numStory]
+	if err := fetch(ctx, feedKind, feed.Name(), fmt.Sprintf(h.urlStory, feed.Name()), &storyIDs); err != nil {
+		return storyIDs, fmt.Errorf("error fetching story: %w", err)
 	}
 
 	return storyIDs, nil
 }
 
-func (h *HackerNews) fetchComment(commentID int, level int) (Comment, error) {
+func (h *HackerNews) fetchComment(ctx context.Context, commentID int, level int) (Comment, error) {
 	var comment Comment
 
-	resp, err := h.client.Get(fmt.Sprintf(h.urlItem, commentID))
-	if err != nil {
+	if err := h.fetch(ctx, itemKind, strconv.Itoa(commentID), fmt.Sprintf(h.urlItem, commentID), &comment); err != nil {
 		return comment, fmt.Errorf("error fetching comment: %w", err)
 	}
 
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if err := json.NewDecoder(resp.Body).Decode(&comment); err != nil {
-		return comment, fmt.Errorf("error decoding comment: %w", err)
-	}
+	var err error
 
 	comment.Text, err = htmltomarkdown.ConvertString(comment.Text)
 	if err != nil {
@@ -255,61 +449,288 @@ func (h *HackerNews) fetchComment(commentID int, level int) (Comment, error) {
 	return comment, nil
 }
 
-func (h *HackerNews) fetchComments(commentIDs []int, level int, buffer chan Comment) error {
-	g := errgroup.Group{}
-
-	for _, id := range commentIDs {
-		g.Go(func() error {
-			comment, err := h.fetchComment(id, level)
-			if err != nil {
-				return err
-			}
+// commentJob is a comment to fetch, with the node of its parent, nil for a top-level comment.
+type commentJob struct {
+	id     int
+	parent *CommentNode
+}
 
-			comment.Level = level
-			buffer <- comment
+// fetchComments fetches the comments of commentIDs and all their replies, with a pool of as many workers
+// as requests allowed in flight. Each comment is sent to buffer once fetched, before its replies.
+func (h *HackerNews) fetchComments(ctx context.Context, commentIDs []int, parent *CommentNode,
+	buffer chan<- *CommentNode,
+) error {
+	g, gctx := errgroup.WithContext(ctx)
+	jobs := make(chan commentJob)
+	replies := make(chan []commentJob) // The replies of each comment fetched, to fetch next
 
-			if len(comment.Kids) > 0 {
-				return h.fetchComments(comment.Kids, level+1, buffer)
+	for range cap(h.sem) {
+		g.Go(func() error {
+			for job := range jobs {
+				next, err := h.fetchCommentJob(gctx, job, buffer)
+				if err != nil {
+					return err
+				}
+
+				select {
+				case replies <- next:
+				case <-gctx.Done():
+					return gctx.Err()
+				}
 			}
 
 			return nil
 		})
 	}
 
+	queue := []commentJob{}
+	for _, id := range commentIDs {
+		queue = append(queue, commentJob{id: id, parent: parent})
+	}
+
+	// The queue grows as the replies are known, it is done once empty with no comment in flight
+	for inFlight := 0; len(queue) > 0 || inFlight > 0; {
+		var (
+			next chan<- commentJob
+			head commentJob
+		)
+
+		if len(queue) > 0 {
+			next, head = jobs, queue[0]
+		}
+
+		select {
+		case next <- head:
+			queue = queue[1:]
+			inFlight++
+		case fetched := <-replies:
+			queue = append(queue, fetched...)
+			inFlight--
+		case <-gctx.Done():
+			queue, inFlight = nil, 0
+		}
+	}
+
+	close(jobs)
+
 	if err := g.Wait(); err != nil {
 		return fmt.Errorf("error fetching comment: %w", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("error fetching comment: %w", err)
+	}
+
 	return nil
 }
 
-func (h *HackerNews) formatComment(comments map[int]Comment, id int) string {
-	var res string
+// fetchCommentJob fetches the comment of job and sends it to buffer, and returns its replies to fetch next.
+// A comment that fails is sent as such, without replies, so that it does not fail the whole thread.
+func (h *HackerNews) fetchCommentJob(ctx context.Context, job commentJob, buffer chan<- *CommentNode,
+) ([]commentJob, error) {
+	level := 0
 
-	const gap = "\n\n"
+	if job.parent != nil {
+		level = job.parent.Depth + 1
+	}
 
-	if comment, ok := comments[id]; ok {
-		if comment.Text != "" {
-			blockquotes := strings.Repeat(">", comment.Level+1)
-			separator := ""
-			header := ""
+	comment, err := h.fetchComment(ctx, job.id, level)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
 
-			if comment.Level == 0 {
-				separator = gap + "---" + gap
-				header = "# "
-			}
-			// Remove duplicated links created by htmltomarkdown lib
-			comment.Text = h.linkRegexp.ReplaceAllString(comment.Text, "[link]($1)")
-			res += fmt.Sprintf(separator+blockquotes+header+"%s | %s %s"+gap,
-				comment.By,
-				h.timeAgo(time.Unix(comment.Time, 0)),
-				strings.ReplaceAll(gap+comment.Text, "\n", "\n"+blockquotes))
+		return nil, send(ctx, buffer, &CommentNode{
+			ID:       job.id,
+			By:       "",
+			Text:     "",
+			Time:     0,
+			TimeAgo:  "",
+			Depth:    level,
+			Dead:     false,
+			Deleted:  false,
+			Failed:   true,
+			Kids:     []int{},
+			Parent:   job.parent,
+			Children: []*CommentNode{},
+		})
+	}
+
+	node := &CommentNode{
+		ID:       comment.ID,
+		By:       comment.By,
+		Text:     comment.Text,
+		Time:     comment.Time,
+		TimeAgo:  TimeAgo(time.Unix(comment.Time, 0)),
+		Depth:    comment.Level,
+		Dead:     comment.Dead,
+		Deleted:  comment.Deleted,
+		Failed:   false,
+		Kids:     comment.Kids,
+		Parent:   job.parent,
+		Children: []*CommentNode{},
+	}
+
+	if err := send(ctx, buffer, node); err != nil {
+		return nil, err
+	}
+
+	replies := []commentJob{}
+	for _, id := range comment.Kids {
+		replies = append(replies, commentJob{id: id, parent: node})
+	}
+
+	return replies, nil
+}
+
+// fetch decodes the JSON behind url into value, going through the cache first.
+func (h *HackerNews) fetch(ctx context.Context, k kind, key string, url string, value any) error {
+	data, ok := h.cache.get(k, key, h.offline)
+	if !ok && h.offline {
+		return errOffline
+	}
+
+	if !ok {
+		return h.refetch(ctx, k, key, url, value)
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("error decoding: %w", err)
+	}
+
+	return nil
+}
+
+// refetch decodes the JSON behind url into value, downloading it again whatever the cache holds.
+// The cache is read only when the download fails, or offline.
+func (h *HackerNews) refetch(ctx context.Context, k kind, key string, url string, value any) error {
+	if h.offline {
+		return h.fetch(ctx, k, key, url, value)
+	}
+
+	data, err := h.download(ctx, url)
+	if err != nil {
+		var ok bool
+
+		// Better an outdated item than nothing when the network is gone
+		if data, ok = h.cache.get(k, key, true); !ok {
+			return err
 		}
+	} else {
+		_ = h.cache.put(k, key, data) // The item is still worth showing
+	}
 
-		for _, id := range comment.Kids {
-			res += h.formatComment(comments, id)
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("error decoding: %w", err)
+	}
+
+	return nil
+}
+
+func send(ctx context.Context, buffer chan<- *CommentNode, node *CommentNode) error {
+	select {
+	case buffer <- node:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// download tries again on a transient failure, waiting twice longer each time.
+func (h *HackerNews) download(ctx context.Context, url string) ([]byte, error) {
+	backoff := h.backoff
+
+	for retry := 0; ; retry++ {
+		data, err := h.downloadOnce(ctx, url)
+		if err == nil || retry >= h.maxRetry || !errors.Is(err, errTransient) {
+			return data, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("error downloading: %w", ctx.Err())
+		}
+
+		backoff *= 2
+	}
+}
+
+func (h *HackerNews) downloadOnce(ctx context.Context, url string) ([]byte, error) {
+	select {
+	case h.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("error downloading: %w", ctx.Err())
+	}
+
+	defer func() {
+		<-h.sem
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading: %w", err)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("error downloading: %w", err)
 		}
+
+		return nil, fmt.Errorf("error downloading: %w: %w", errTransient, err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// Never cache an error page
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: %w: %s", errStatus, errTransient, resp.Status)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading: %w: %w", errTransient, err)
+	}
+
+	return data, nil
+}
+
+// readArticle extracts the readable part of the page behind pageURL.
+func (h *HackerNews) readArticle(ctx context.Context, pageURL string) (readability.Article, error) {
+	parsedURL, err := url.ParseRequestURI(pageURL)
+	if err != nil {
+		return readability.Article{}, fmt.Errorf("error parsing url article: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return readability.Article{}, fmt.Errorf("error downloading: %w", err)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return readability.Article{}, fmt.Errorf("error downloading: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		return readability.Article{}, errNotHTML
+	}
+
+	article, err := readability.FromReader(resp.Body, parsedURL)
+	if err != nil {
+		return readability.Article{}, fmt.Errorf("error parsing article: %w", err)
 	}
 
-	return res
+	return article, nil
 }
@@ -1,11 +1,18 @@
 package hackernews
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestStory(t *testing.T) {
@@ -18,10 +25,27 @@ func TestStory(t *testing.T) {
 			return
 		}
 
+		if r.URL.Path == "/v0/newstories.json" {
+			mockStoryIDs := []int{40077533}
+			jsonData, _ := json.Marshal(mockStoryIDs)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		if r.URL.Path == "/v0/askstories.json" {
+			mockStoryIDs := []int{16582136, 43332658}
+			jsonData, _ := json.Marshal(mockStoryIDs)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
 		if r.URL.Path == "/v0/item/43332658.json" {
 			story := Story{
 				By:         "btilly",
 				NumComment: 3,
+				WebURL:     "",
 				ID:         43332658,
 				Kids:       []int{43339316, 43335480},
 				Score:      1421,
@@ -42,6 +66,7 @@ func TestStory(t *testing.T) {
 			story := Story{
 				By:         "Cogito",
 				NumComment: 436,
+				WebURL:     "",
 				ID:         16582136,
 				Kids:       []int{16582247},
 				Score:      6015,
@@ -62,6 +87,7 @@ func TestStory(t *testing.T) {
 			story := Story{
 				By:         "bratao",
 				NumComment: 923,
+				WebURL:     "",
 				ID:         40077533,
 				Kids:       []int{},
 				Score:      2199,
@@ -82,21 +108,168 @@ func TestStory(t *testing.T) {
 	}))
 	defer mockServer.Close()
 
-	h := NewHackerNews(mockServer.URL+"/v0/topstories.json",
-		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d", 3)
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
+
+	tests := []struct {
+		feed        Feed
+		expectedIDs []int
+	}{
+		{feed: TopFeed, expectedIDs: []int{43332658, 16582136, 40077533}},
+		{feed: NewFeed, expectedIDs: []int{40077533}},
+		{feed: AskFeed, expectedIDs: []int{16582136, 43332658}},
+	}
+
+	for _, tt := range tests {
+		t.Run("Check story IDs for "+tt.feed.Name(), func(t *testing.T) {
+			stories, err := h.Story(context.Background(), tt.feed)
+			if err != nil {
+				t.Fatalf("Story() error = %v", err)
+			}
+
+			storyIDs := []int{}
+			for _, story := range stories {
+				storyIDs = append(storyIDs, story.ID)
+			}
+
+			if !slices.Equal(storyIDs, tt.expectedIDs) {
+				t.Errorf("storyIDs = %v; want %v", storyIDs, tt.expectedIDs)
+			}
+		})
+	}
+}
+
+func TestStoryPage(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0/topstories.json" {
+			mockStoryIDs := []int{43332658, 16582136, 40077533}
+			jsonData, _ := json.Marshal(mockStoryIDs)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, "/v0/item/") {
+			story := Story{
+				By:         "pg",
+				NumComment: 0,
+				WebURL:     "",
+				ID:         0,
+				Kids:       []int{},
+				Score:      1,
+				Time:       1741702475,
+				PostTitle:  strings.TrimPrefix(r.URL.Path, "/v0/item/"),
+				URL:        "ycombinator.com",
+				Rank:       0,
+				TimeAgo:    "x days ago",
+				URLHost:    "ycombinator.com",
+			}
+			jsonData, _ := json.Marshal(story)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 2, nil)
+
+	tests := []struct {
+		page          int
+		expectedTitle []string
+	}{
+		{page: 0, expectedTitle: []string{"1. 43332658.json", "2. 16582136.json"}},
+		{page: 1, expectedTitle: []string{"3. 40077533.json"}},
+		{page: 2, expectedTitle: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Check story page %d", tt.page), func(t *testing.T) {
+			stories, err := h.StoryPage(context.Background(), TopFeed, tt.page)
+			if err != nil {
+				t.Fatalf("StoryPage(%d) returned an error: %v", tt.page, err)
+			}
+
+			if len(stories) != len(tt.expectedTitle) {
+				t.Fatalf("len(stories) = %v; want %v", len(stories), len(tt.expectedTitle))
+			}
+
+			for i, story := range stories {
+				if got := story.Title(); got != tt.expectedTitle[i] {
+					t.Errorf("story.Title() = %v; want %v", got, tt.expectedTitle[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/search" {
+			if r.URL.Query().Get("query") == "birthday y combinator" {
+				// The second hit cannot be read, the HN API is never asked about the others
+				_, _ = w.Write([]byte(`{"hits":[` +
+					`{"objectID":"43332658","title":"Happy 20th birthday, Y Combinator","author":"btilly",` +
+					`"url":"https://www.ycombinator.com/blog","points":1421,"num_comments":3,` +
+					`"created_at_i":1741702475,"children":[43339316,43335480]},` +
+					`{"objectID":"not a number","title":"Broken"},` +
+					`{"objectID":"43332659","title":"Y Combinator turns 20","author":"dang"}]}`))
+
+				return
+			}
 
-	storyIDs, _ := h.Story()
+			_, _ = w.Write([]byte(`{"hits":[]}`))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
 
 	tests := []struct {
-		expectedCount int
+		query         string
+		expectedTitle []string
 	}{
-		{expectedCount: 3},
+		{
+			query:         "birthday y combinator",
+			expectedTitle: []string{"1. Happy 20th birthday, Y Combinator", "2. Y Combinator turns 20"},
+		},
+		{query: "nothing", expectedTitle: []string{}},
 	}
 
 	for _, tt := range tests {
-		t.Run("Check number of story IDs", func(t *testing.T) {
-			if got := len(storyIDs); got != tt.expectedCount {
-				t.Errorf("len(storyIDs) = %v; want %v", got, tt.expectedCount)
+		t.Run(tt.query, func(t *testing.T) {
+			stories, err := h.Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Search(%s) returned an error: %v", tt.query, err)
+			}
+
+			if len(stories) != len(tt.expectedTitle) {
+				t.Fatalf("len(stories) = %v; want %v", len(stories), len(tt.expectedTitle))
+			}
+
+			for i, story := range stories {
+				if got := story.Title(); got != tt.expectedTitle[i] {
+					t.Errorf("story.Title() = %v; want %v", got, tt.expectedTitle[i])
+				}
+			}
+
+			if len(stories) > 0 && (stories[0].URLHost != "www.ycombinator.com" || len(stories[0].Kids) != 2 ||
+				stories[0].Score != 1421) {
+				t.Errorf("stories[0] = %+v; want the fields of the hit", stories[0])
 			}
 		})
 	}
@@ -106,13 +279,15 @@ func TestComment(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/v0/item/43339316.json" {
 			comment := Comment{
-				By:     "Dave_Rosenthal",
-				ID:     43339316,
-				Parent: 43332658,
-				Kids:   []int{43340657},
-				Text:   "I worked with pg in a three-person startup in a basement in Harvard square just before he started YC",
-				Time:   1741746022,
-				Level:  1,
+				By:      "Dave_Rosenthal",
+				ID:      43339316,
+				Parent:  43332658,
+				Kids:    []int{43340657},
+				Text:    "I worked with pg in a three-person startup in a basement in Harvard square just before he started YC",
+				Time:    1741746022,
+				Level:   1,
+				Dead:    false,
+				Deleted: false,
 			}
 			jsonData, _ := json.Marshal(comment)
 			_, _ = w.Write(jsonData)
@@ -122,13 +297,15 @@ func TestComment(t *testing.T) {
 
 		if r.URL.Path == "/v0/item/43340657.json" {
 			comment := Comment{
-				By:     "knuckleheadsmif",
-				ID:     43340657,
-				Parent: 43339316,
-				Text:   "In the mid 90s (1996?) So myself and 3 others worked for Intuit and we traveled, from Mountain View CA",
-				Time:   1741764049,
-				Level:  2,
-				Kids:   []int{},
+				By:      "knuckleheadsmif",
+				ID:      43340657,
+				Parent:  43339316,
+				Text:    "In the mid 90s (1996?) So myself and 3 others worked for Intuit and we traveled, from Mountain View CA",
+				Time:    1741764049,
+				Level:   2,
+				Dead:    false,
+				Deleted: false,
+				Kids:    []int{},
 			}
 			jsonData, _ := json.Marshal(comment)
 			_, _ = w.Write(jsonData)
@@ -138,13 +315,15 @@ func TestComment(t *testing.T) {
 
 		if r.URL.Path == "/v0/item/43335480.json" {
 			comment := Comment{
-				By:     "CSMastermind",
-				ID:     43335480,
-				Parent: 43332658,
-				Text:   "I remember the initial PG announcement about the founder",
-				Time:   1741717262,
-				Kids:   []int{},
-				Level:  1,
+				By:      "CSMastermind",
+				ID:      43335480,
+				Parent:  43332658,
+				Text:    "I remember the initial PG announcement about the founder",
+				Time:    1741717262,
+				Kids:    []int{},
+				Level:   1,
+				Dead:    false,
+				Deleted: false,
 			}
 			jsonData, _ := json.Marshal(comment)
 			_, _ = w.Write(jsonData)
@@ -159,6 +338,7 @@ func TestComment(t *testing.T) {
 	story := Story{
 		By:         "btilly",
 		NumComment: 3,
+		WebURL:     "",
 		ID:         43332658,
 		Kids:       []int{43339316, 43335480},
 		Score:      1421,
@@ -170,29 +350,267 @@ func TestComment(t *testing.T) {
 		URLHost:    "ycombinator.com",
 	}
 
-	h := NewHackerNews(mockServer.URL+"/v0/topstories.json",
-		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d", 3)
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
+
+	comments, _ := h.Comment(context.Background(), story)
 
-	comment, _ := h.Comment(story)
+	if len(comments) != 2 || len(comments[0].Children) != 1 {
+		t.Fatalf("Comment() did not follow the kids order of the thread")
+	}
 
 	tests := []struct {
-		substring string
-		expected  bool
+		node           *CommentNode
+		expectedBy     string
+		expectedDepth  int
+		expectedParent *CommentNode
 	}{
-		{"Dave_Rosenthal", true},
-		{"knuckleheadsmif", true},
-		{"CSMastermind", true},
+		{comments[0], "Dave_Rosenthal", 0, nil},
+		{comments[0].Children[0], "knuckleheadsmif", 1, comments[0]},
+		{comments[1], "CSMastermind", 0, nil},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.substring, func(t *testing.T) {
-			if got := strings.Contains(comment, tt.substring); got != tt.expected {
-				t.Errorf("strings.Contains(comment, %s) = %v; want %v", tt.substring, got, tt.expected)
+		t.Run(tt.expectedBy, func(t *testing.T) {
+			if tt.node.By != tt.expectedBy {
+				t.Errorf("node.By = %v; want %v", tt.node.By, tt.expectedBy)
+			}
+
+			if tt.node.Depth != tt.expectedDepth {
+				t.Errorf("node.Depth = %v; want %v", tt.node.Depth, tt.expectedDepth)
+			}
+
+			if tt.node.Parent != tt.expectedParent {
+				t.Errorf("node.Parent = %v; want %v", tt.node.Parent, tt.expectedParent)
 			}
 		})
 	}
 }
 
+func TestCommentStreamCancel(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done() // Never answers, only the cancellation ends the request
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
+
+	story := Story{
+		By:         "btilly",
+		NumComment: 3,
+		WebURL:     "",
+		ID:         43332658,
+		Kids:       []int{43339316, 43335480},
+		Score:      1421,
+		Time:       1741702475,
+		PostTitle:  "Happy 20th birthday, Y Combinator",
+		URL:        "/ycombinator",
+		Rank:       1,
+		TimeAgo:    "x days ago",
+		URLHost:    "ycombinator.com",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan *CommentNode, story.NumComment)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- h.CommentStream(ctx, story, out)
+	}()
+
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("CommentStream() = %v; want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("CommentStream() did not stop once cancelled")
+	}
+
+	if _, ok := <-out; ok {
+		t.Errorf("CommentStream() did not close out")
+	}
+}
+
+func TestCommentWorkers(t *testing.T) {
+	const numComment = 500
+
+	release := make(chan struct{})
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release // Every comment waits, so that all of them would be in flight at once
+
+		var id int
+
+		_, _ = fmt.Sscanf(r.URL.Path, "/v0/item/%d.json", &id)
+		_, _ = fmt.Fprintf(w, `{"id":%d,"by":"pg","text":"Comment %d"}`, id, id)
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
+	h.SetLimits(4, 0)
+
+	story := Story{} //nolint:exhaustruct // Only the comments matter
+	for i := range numComment {
+		story.Kids = append(story.Kids, i+1)
+	}
+
+	before := runtime.NumGoroutine()
+	out := make(chan *CommentNode, numComment)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- h.CommentStream(context.Background(), story, out)
+	}()
+
+	time.Sleep(100 * time.Millisecond) // Leaves the time to start a goroutine per comment, if it did
+
+	// The workers and the requests they have in flight, not one goroutine per comment
+	if started := runtime.NumGoroutine() - before; started > numComment/10 {
+		t.Errorf("CommentStream() started %d goroutines for %d comments; want a bounded pool", started, numComment)
+	}
+
+	close(release)
+
+	if err := <-errs; err != nil {
+		t.Fatalf("CommentStream() = %v", err)
+	}
+
+	if len(out) != numComment {
+		t.Errorf("CommentStream() sent %d comments; want %d", len(out), numComment)
+	}
+}
+
+func TestDownloadRetry(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int // Answered in turn, then 200
+		expectedAttempts int32
+		expectedErr      bool
+	}{
+		{"no failure", []int{}, 1, false},
+		{"transient failures", []int{http.StatusBadGateway, http.StatusTooManyRequests}, 3, false},
+		{"too many failures", []int{500, 500, 500, 500}, 4, true},
+		{"permanent failure", []int{http.StatusNotFound}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				attempt := int(attempts.Add(1)) - 1
+				if attempt < len(tt.statuses) {
+					w.WriteHeader(tt.statuses[attempt])
+
+					return
+				}
+
+				_, _ = w.Write([]byte("[]"))
+			}))
+			defer mockServer.Close()
+
+			h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+				mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+				mockServer.URL+"/api/v1/search?tags=story&query=%s",
+				mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
+			h.backoff = time.Millisecond
+
+			_, err := h.download(context.Background(), mockServer.URL)
+
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("download() error = %v; want error %v", err, tt.expectedErr)
+			}
+
+			if attempts.Load() != tt.expectedAttempts {
+				t.Errorf("download() attempts = %d; want %d", attempts.Load(), tt.expectedAttempts)
+			}
+		})
+	}
+}
+
+func TestCommentFailed(t *testing.T) {
+	const maxConcurrency = 2
+
+	var inFlight, maxInFlight atomic.Int32
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for current := maxInFlight.Load(); n > current && !maxInFlight.CompareAndSwap(current, n); {
+			current = maxInFlight.Load()
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		if r.URL.Path == "/v0/item/2.json" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		var id int
+
+		_, _ = fmt.Sscanf(r.URL.Path, "/v0/item/%d.json", &id)
+		jsonData, _ := json.Marshal(Comment{
+			ID:      id,
+			By:      "pg",
+			Text:    "Reply",
+			Time:    1741717262,
+			Kids:    []int{},
+			Parent:  0,
+			Dead:    false,
+			Deleted: false,
+			Level:   0,
+		})
+		_, _ = w.Write(jsonData)
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
+	h.SetLimits(maxConcurrency, 1)
+	h.backoff = time.Millisecond
+
+	story := Story{ //nolint:exhaustruct // Only the kids matter
+		ID:         1,
+		Kids:       []int{2, 3, 4, 5, 6},
+		NumComment: 5,
+	}
+
+	comments, err := h.Comment(context.Background(), story)
+	if err != nil {
+		t.Fatalf("Comment() failed the whole thread: %v", err)
+	}
+
+	if len(comments) != len(story.Kids) {
+		t.Fatalf("Comment() returned %d comments; want %d", len(comments), len(story.Kids))
+	}
+
+	for _, comment := range comments {
+		if comment.Failed != (comment.ID == 2) {
+			t.Errorf("comment %d Failed = %v; want %v", comment.ID, comment.Failed, comment.ID == 2)
+		}
+	}
+
+	if maxInFlight.Load() > maxConcurrency {
+		t.Errorf("Comment() sent %d requests at once; want at most %d", maxInFlight.Load(), maxConcurrency)
+	}
+}
+
 func TestArticle(t *testing.T) {
 	httpContent := `<html><head><title>Hacker News</title></head><body><h1>new | past | comments | ask | show | jobs | submit</h1></body></html>` //nolint: lll // ...
 
@@ -202,12 +620,15 @@ func TestArticle(t *testing.T) {
 	}))
 	defer mockServer.Close()
 
-	h := NewHackerNews(mockServer.URL+"/v0/topstories.json",
-		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d", 3)
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
 
 	story := Story{
 		By:         "btilly",
 		NumComment: 3,
+		WebURL:     "",
 		ID:         43332658,
 		Kids:       []int{43339316, 43335480},
 		Score:      1421,
@@ -219,7 +640,7 @@ func TestArticle(t *testing.T) {
 		TimeAgo:    "x days ago",
 	}
 
-	article, _ := h.Article(story)
+	article, _ := h.Article(context.Background(), story)
 
 	tests := []struct {
 		substring string
@@ -0,0 +1,355 @@
+package hackernews
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	errStream = errors.New("stream ended")
+	errEvent  = errors.New("unexpected event")
+)
+
+// maxStreamBackoff bounds the wait before a stream that failed to open is tried again.
+const maxStreamBackoff = time.Minute
+
+// StoryUpdate is a change of a feed pushed by the HN API.
+type StoryUpdate struct {
+	IDs     []int   // Story IDs of the feed in rank order, nil when the order did not change
+	Stories []Story // Stories new in the feed, or whose score or comments changed
+}
+
+// event is a put or a patch of the Firebase streaming API, data replaces or is merged into path.
+type event struct {
+	name string
+	Path string          `json:"path"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Watch sends the changes of feed as they happen, until ctx is cancelled, and closes out at the end.
+// The first update holds the IDs of the feed when it was opened, and so does the first one after the
+// streams are opened again, Firebase closes those left idle. Offline, nothing changes.
+func (h *HackerNews) Watch(ctx context.Context, feed Feed, out chan<- StoryUpdate) error {
+	defer close(out)
+
+	if h.offline {
+		return nil
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	feedEvents := make(chan event)
+	updateEvents := make(chan event)
+
+	g.Go(func() error {
+		return h.follow(ctx, fmt.Sprintf(h.urlStory, feed.Name()), feedEvents)
+	})
+	g.Go(func() error {
+		return h.follow(ctx, h.urlUpdates, updateEvents)
+	})
+	g.Go(func() error {
+		var storyIDs []int
+
+		for {
+			var (
+				update StoryUpdate
+				err    error
+			)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case e := <-feedEvents:
+				previous := storyIDs
+
+				storyIDs, err = applyIDs(slices.Clone(storyIDs), e)
+				if err != nil {
+					return err
+				}
+
+				// The next pages follow the live order
+				h.mutex.Lock()
+				h.storyIDs[feed] = storyIDs
+				h.mutex.Unlock()
+
+				update.IDs = slices.Clone(storyIDs)
+
+				if previous != nil {
+					update.Stories = h.refreshStories(ctx, storyIDs, func(id int) bool {
+						return !slices.Contains(previous, id)
+					})
+				}
+			case e := <-updateEvents:
+				var changed []int
+
+				changed, err = changedItems(e)
+				if err != nil {
+					return err
+				}
+
+				update.Stories = h.refreshStories(ctx, storyIDs, func(id int) bool {
+					return slices.Contains(changed, id)
+				})
+			}
+
+			if update.IDs == nil && len(update.Stories) == 0 {
+				continue
+			}
+
+			select {
+			case out <- update:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("error watching story: %w", err)
+	}
+
+	return nil
+}
+
+// refreshStories downloads again the stories of storyIDs picked, whatever the cache holds. The downloads
+// share the semaphore of the other requests, a story that fails is left out until it changes again.
+func (h *HackerNews) refreshStories(ctx context.Context, storyIDs []int, picked func(int) bool) []Story {
+	g := errgroup.Group{}
+	refreshed := make([]*Story, len(storyIDs))
+
+	for rank, storyID := range storyIDs {
+		if !picked(storyID) {
+			continue
+		}
+
+		g.Go(func() error {
+			story, err := h.refreshStory(ctx, storyID, rank)
+			if err == nil {
+				refreshed[rank] = &story
+			}
+
+			return nil
+		})
+	}
+
+	_ = g.Wait() // Nothing fails the refresh as a whole
+
+	stories := []Story{}
+
+	for _, story := range refreshed {
+		if story != nil {
+			stories = append(stories, *story)
+		}
+	}
+
+	return stories
+}
+
+func (h *HackerNews) refreshStory(ctx context.Context, storyID int, rank int) (Story, error) {
+	var story Story
+
+	data, err := h.download(ctx, fmt.Sprintf(h.urlItem, storyID))
+	if err != nil {
+		return story, fmt.Errorf("error fetching story: %w", err)
+	}
+
+	_ = h.cache.put(itemKind, strconv.Itoa(storyID), data) // The story is still worth showing
+
+	if err := json.Unmarshal(data, &story); err != nil {
+		return story, fmt.Errorf("error decoding story: %w", err)
+	}
+
+	if err := h.decorate(&story, storyID, rank); err != nil {
+		return story, err
+	}
+
+	return story, nil
+}
+
+// follow listens to the Firebase stream behind url again each time it ends, until ctx is cancelled.
+// It waits twice longer after each failure in a row to open it, up to maxStreamBackoff.
+func (h *HackerNews) follow(ctx context.Context, url string, events chan<- event) error {
+	backoff := h.backoff
+
+	for {
+		opened, _ := h.listen(ctx, url, events) // The stream is opened again whatever ended it
+		if ctx.Err() != nil {
+			return fmt.Errorf("error listening: %w", ctx.Err())
+		}
+
+		if opened {
+			backoff = h.backoff
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("error listening: %w", ctx.Err())
+		}
+
+		if !opened {
+			backoff = min(backoff*2, maxStreamBackoff)
+		}
+	}
+}
+
+// listen sends the puts and patches of the Firebase stream behind url, until the stream or ctx ends,
+// and tells whether the stream was opened at all.
+func (h *HackerNews) listen(ctx context.Context, url string, events chan<- event) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("error listening: %w", err)
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := h.streamClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error listening: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20) // A whole feed comes in one line
+	name := ""
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			switch name {
+			case "put", "patch":
+				e := event{name: name, Path: "", Data: nil}
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &e); err != nil {
+					return true, fmt.Errorf("error decoding event: %w", err)
+				}
+
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return true, ctx.Err()
+				}
+			case "cancel", "auth_revoked":
+				return true, fmt.Errorf("%w: %s", errStream, name)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return true, fmt.Errorf("error listening: %w", err)
+	}
+
+	return true, errStream
+}
+
+// applyIDs returns the IDs of a feed once e is applied to them.
+func applyIDs(storyIDs []int, e event) ([]int, error) {
+	if e.Path == "/" && e.name == "put" {
+		var replaced []int
+		if err := json.Unmarshal(e.Data, &replaced); err != nil {
+			return storyIDs, fmt.Errorf("error decoding event: %w", err)
+		}
+
+		return slices.DeleteFunc(replaced, isNoStory), nil
+	}
+
+	changes := map[string]*int{}
+
+	if e.Path == "/" {
+		if err := json.Unmarshal(e.Data, &changes); err != nil {
+			return storyIDs, fmt.Errorf("error decoding event: %w", err)
+		}
+	} else {
+		var storyID *int
+		if err := json.Unmarshal(e.Data, &storyID); err != nil {
+			return storyIDs, fmt.Errorf("error decoding event: %w", err)
+		}
+
+		changes[strings.TrimPrefix(e.Path, "/")] = storyID
+	}
+
+	// Ranks are set in order, then removed, whatever the order of the keys of the event
+	ranks := map[int]*int{}
+
+	for key, storyID := range changes {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 {
+			return storyIDs, fmt.Errorf("%w: %s", errEvent, e.Path)
+		}
+
+		ranks[i] = storyID
+	}
+
+	removed := -1
+
+	for _, i := range slices.Sorted(maps.Keys(ranks)) {
+		if ranks[i] == nil {
+			if removed < 0 {
+				removed = i
+			}
+
+			continue
+		}
+
+		for len(storyIDs) <= i {
+			storyIDs = append(storyIDs, 0)
+		}
+
+		storyIDs[i] = *ranks[i]
+	}
+
+	// A removed rank is always one of the last ones
+	if removed >= 0 {
+		storyIDs = storyIDs[:min(removed, len(storyIDs))]
+	}
+
+	// A rank skipped over holds no story
+	return slices.DeleteFunc(storyIDs, isNoStory), nil
+}
+
+// isNoStory tells an ID that stands for no story, a null of the feed or a rank skipped over.
+func isNoStory(storyID int) bool {
+	return storyID == 0
+}
+
+// changedItems returns the IDs of the items changed according to an event of the updates stream.
+func changedItems(e event) ([]int, error) {
+	var updates struct {
+		Items []int `json:"items"`
+	}
+
+	var err error
+
+	switch e.Path {
+	case "/":
+		err = json.Unmarshal(e.Data, &updates)
+	case "/items":
+		err = json.Unmarshal(e.Data, &updates.Items)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding event: %w", err)
+	}
+
+	return updates.Items, nil
+}
@@ -0,0 +1,160 @@
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	updates := make(chan string, 1) // Events of the updates stream, sent once the new story is fetched
+	feedStreams := atomic.Int32{}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		send := func(name string, data string) {
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+			w.(http.Flusher).Flush()
+		}
+
+		switch r.URL.Path {
+		case "/v0/topstories.json":
+			send("put", `{"path":"/","data":[1,2]}`)
+
+			// Firebase closes the first stream, the feed is listened to again
+			if feedStreams.Add(1) == 1 {
+				return
+			}
+
+			send("keep-alive", "null")
+			send("patch", `{"path":"/","data":{"0":3,"1":1,"2":2}}`)
+		case "/v0/updates.json":
+			send("put", <-updates)
+		default:
+			var id int
+
+			_, _ = fmt.Sscanf(r.URL.Path, "/v0/item/%d.json", &id)
+			if id == 3 {
+				updates <- `{"path":"/","data":{"items":[1,2,42],"profiles":["pg"]}}`
+			}
+
+			// A story that fails is left out of the update, the watch goes on
+			if id == 1 {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			jsonData, _ := json.Marshal(Story{ //nolint:exhaustruct // Only the score matters
+				PostTitle: fmt.Sprintf("Story %d", id),
+				URL:       "https://example.com",
+				Score:     id * 10,
+			})
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		<-r.Context().Done()
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 3, nil)
+	h.backoff = time.Millisecond // The stream is opened again right away
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan StoryUpdate)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- h.Watch(ctx, TopFeed, out)
+	}()
+
+	tests := []struct {
+		name            string
+		expectedIDs     []int
+		expectedStories map[int]int // Score of each story, by rank
+	}{
+		{"opened", []int{1, 2}, map[int]int{}},
+		{"opened again", []int{1, 2}, map[int]int{}},
+		{"new story on top", []int{3, 1, 2}, map[int]int{0: 30}},
+		{"score changed, failed story left out", nil, map[int]int{2: 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update StoryUpdate
+
+			select {
+			case update = <-out:
+			case <-time.After(time.Second):
+				t.Fatalf("Watch() sent no update")
+			}
+
+			if !slices.Equal(update.IDs, tt.expectedIDs) {
+				t.Errorf("update.IDs = %v; want %v", update.IDs, tt.expectedIDs)
+			}
+
+			if len(update.Stories) != len(tt.expectedStories) {
+				t.Fatalf("update.Stories = %v; want %d stories", update.Stories, len(tt.expectedStories))
+			}
+
+			for _, story := range update.Stories {
+				if score, ok := tt.expectedStories[story.Rank]; !ok || story.Score != score {
+					t.Errorf("story %d at rank %d has score %d; want %v", story.ID, story.Rank, story.Score, score)
+				}
+			}
+		})
+	}
+
+	cancel()
+
+	if _, ok := <-out; ok {
+		t.Errorf("Watch() did not close out")
+	}
+
+	if err := <-errs; err == nil {
+		t.Errorf("Watch() = nil; want the cancellation")
+	}
+}
+
+func TestApplyIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    event
+		expected []int
+	}{
+		{"put replaces", event{name: "put", Path: "/", Data: json.RawMessage(`[4,null,5]`)}, []int{4, 5}},
+		{"put sets a rank", event{name: "put", Path: "/1", Data: json.RawMessage(`9`)}, []int{1, 9, 3}},
+		{"put removes the last rank", event{name: "put", Path: "/2", Data: json.RawMessage(`null`)}, []int{1, 2}},
+		{"put adds a rank", event{name: "put", Path: "/3", Data: json.RawMessage(`4`)}, []int{1, 2, 3, 4}},
+		{"patch merges", event{name: "patch", Path: "/", Data: json.RawMessage(`{"0":3,"2":1}`)}, []int{3, 2, 1}},
+		{
+			"patch sets ranks then removes the last ones",
+			event{name: "patch", Path: "/", Data: json.RawMessage(`{"2":null,"1":7,"0":8}`)},
+			[]int{8, 7},
+		},
+		{"rank skipped over left out", event{name: "put", Path: "/5", Data: json.RawMessage(`6`)}, []int{1, 2, 3, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyIDs([]int{1, 2, 3}, tt.event)
+			if err != nil {
+				t.Fatalf("applyIDs() error = %v", err)
+			}
+
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("applyIDs() = %v; want %v", got, tt.expected)
+			}
+		})
+	}
+}
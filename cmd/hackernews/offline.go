@@ -0,0 +1,107 @@
+package hackernews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	errOffline    = errors.New("not available offline")
+	errIncomplete = errors.New("comments missing")
+)
+
+// NewStore keeps everything Sync downloads, an entry is never considered fresh
+// so that a sync always downloads it again.
+func NewStore(dir string) *Cache {
+	return NewCache(dir, 0, 0, 0)
+}
+
+// NewOfflineHackerNews serves stories, comments, and articles from store only, without any network access.
+func NewOfflineHackerNews(urlStory string, urlItem string, urlWebItem string, urlSearch string, urlUpdates string,
+	urlUser string, numStory int, store *Cache,
+) *HackerNews {
+	h := NewHackerNews(urlStory, urlItem, urlWebItem, urlSearch, urlUpdates, urlUser, numStory, store)
+	h.offline = true
+
+	return h
+}
+
+// SyncFailure is a story saved without its comments, some of them, or its article.
+type SyncFailure struct {
+	Story Story
+	Err   error
+}
+
+// SyncResult counts the stories saved, and tells which of them are missing their comments or article.
+type SyncResult struct {
+	Stories  int
+	Failures []SyncFailure
+}
+
+// Sync downloads the front page with its comments and articles into store. A story whose comments,
+// any of them, or article fail is recorded as a failure, the other stories are still downloaded.
+func (h *HackerNews) Sync(ctx context.Context, store *Cache) (SyncResult, error) {
+	syncer := NewHackerNews(h.urlStory, h.urlItem, h.urlWebItem, h.urlSearch, h.urlUpdates, h.urlUser,
+		h.numStory, store)
+	syncer.sem = h.sem
+	syncer.maxRetry = h.maxRetry
+	syncer.backoff = h.backoff
+
+	stories, err := syncer.Story(ctx, TopFeed)
+	if err != nil {
+		return SyncResult{Stories: 0, Failures: nil}, fmt.Errorf("error syncing story: %w", err)
+	}
+
+	// One slot per story, so that the failures come in the order of the stories
+	errs := make([]error, len(stories))
+	g := errgroup.Group{}
+
+	for i, story := range stories {
+		g.Go(func() error {
+			comments, err := syncer.Comment(ctx, story)
+			if err != nil {
+				errs[i] = fmt.Errorf("error syncing comment: %w", err)
+			} else if failed := countFailed(comments); failed > 0 {
+				errs[i] = fmt.Errorf("error syncing comment: %w: %d of %d", errIncomplete, failed, story.NumComment)
+			}
+
+			if story.URL != "" {
+				if _, err := syncer.Article(ctx, story); err != nil {
+					errs[i] = errors.Join(errs[i], fmt.Errorf("error syncing article: %w", err))
+				}
+			}
+
+			return nil
+		})
+	}
+
+	_ = g.Wait() // The failures are kept in errs
+
+	result := SyncResult{Stories: len(stories), Failures: []SyncFailure{}}
+
+	for i, err := range errs {
+		if err != nil {
+			result.Failures = append(result.Failures, SyncFailure{Story: stories[i], Err: err})
+		}
+	}
+
+	return result, nil
+}
+
+// countFailed tells how many comments of the threads could not be fetched, their replies are not known.
+func countFailed(nodes []*CommentNode) int {
+	failed := 0
+
+	for _, node := range nodes {
+		if node.Failed {
+			failed++
+		}
+
+		failed += countFailed(node.Children)
+	}
+
+	return failed
+}
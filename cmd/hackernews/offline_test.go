@@ -0,0 +1,188 @@
+package hackernews
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSync(t *testing.T) {
+	var mockServer *httptest.Server
+
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0/topstories.json" {
+			mockStoryIDs := []int{43332658, 43332659, 43332660}
+			jsonData, _ := json.Marshal(mockStoryIDs)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		if r.URL.Path == "/v0/item/43332658.json" {
+			story := Story{
+				By:         "btilly",
+				NumComment: 1,
+				WebURL:     "",
+				ID:         43332658,
+				Kids:       []int{43335480},
+				Score:      1421,
+				Time:       1741702475,
+				PostTitle:  "Happy 20th birthday, Y Combinator",
+				URL:        mockServer.URL + "/ycombinator",
+				Rank:       0,
+				TimeAgo:    "x days ago",
+				URLHost:    "ycombinator.com",
+			}
+			jsonData, _ := json.Marshal(story)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		// Its article cannot be read, the story and its comments are still synced
+		if r.URL.Path == "/v0/item/43332659.json" {
+			story := Story{
+				By:         "dang",
+				NumComment: 0,
+				WebURL:     "",
+				ID:         43332659,
+				Kids:       []int{},
+				Score:      42,
+				Time:       1741702475,
+				PostTitle:  "Paywalled",
+				URL:        mockServer.URL + "/paywall",
+				Rank:       1,
+				TimeAgo:    "x days ago",
+				URLHost:    "paywall.com",
+			}
+			jsonData, _ := json.Marshal(story)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		// One of its comments keeps failing, the story is synced with the others
+		if r.URL.Path == "/v0/item/43332660.json" {
+			story := Story{
+				By:         "pg",
+				NumComment: 2,
+				WebURL:     "",
+				ID:         43332660,
+				Kids:       []int{43335480, 43335490},
+				Score:      42,
+				Time:       1741702475,
+				PostTitle:  "Flaky comments",
+				URL:        "",
+				Rank:       2,
+				TimeAgo:    "x days ago",
+				URLHost:    "",
+			}
+			jsonData, _ := json.Marshal(story)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		if r.URL.Path == "/v0/item/43335490.json" {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		if r.URL.Path == "/v0/item/43335480.json" {
+			comment := Comment{
+				By:      "CSMastermind",
+				ID:      43335480,
+				Parent:  43332658,
+				Text:    "I remember the initial PG announcement about the founder",
+				Time:    1741717262,
+				Kids:    []int{},
+				Level:   0,
+				Dead:    false,
+				Deleted: false,
+			}
+			jsonData, _ := json.Marshal(comment)
+			_, _ = w.Write(jsonData)
+
+			return
+		}
+
+		if r.URL.Path == "/ycombinator" {
+			_, _ = w.Write([]byte(`<html><head><title>YC</title></head><body><p>Make something people want</p></body></html>`))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	storyURL := mockServer.URL + "/v0/%sstories.json"
+	itemURL := mockServer.URL + "/v0/item/%d.json"
+	webURL := mockServer.URL + "/item?id=%d"
+	searchURL := mockServer.URL + "/api/v1/search?tags=story&query=%s"
+	updatesURL := mockServer.URL + "/v0/updates.json"
+	userURL := mockServer.URL + "/v0/user/%s.json"
+	store := NewStore(t.TempDir())
+
+	h := NewHackerNews(storyURL, itemURL, webURL, searchURL, updatesURL, userURL, 3, nil)
+	h.backoff = time.Millisecond // The failing comment is retried, no need to wait
+
+	result, err := h.Sync(context.Background(), store)
+	if err != nil || result.Stories != 3 {
+		t.Fatalf("Sync() = %v, %v; want 3 stories, nil", result, err)
+	}
+
+	if len(result.Failures) != 2 || result.Failures[0].Story.ID != 43332659 || result.Failures[1].Story.ID != 43332660 {
+		t.Fatalf("Sync() failures = %v; want the paywalled story and the one with a failed comment", result.Failures)
+	}
+
+	if !errors.Is(result.Failures[1].Err, errIncomplete) {
+		t.Errorf("Sync() failure = %v; want the comments missing", result.Failures[1].Err)
+	}
+
+	mockServer.Close()
+
+	h = NewOfflineHackerNews(storyURL, itemURL, webURL, searchURL, updatesURL, userURL, 3, store)
+
+	stories, err := h.Story(context.Background(), TopFeed)
+	if err != nil || len(stories) != 3 {
+		t.Fatalf("Story() = %v, %v; want 3 stories", stories, err)
+	}
+
+	comments, _ := h.Comment(context.Background(), stories[0])
+	article, _ := h.Article(context.Background(), stories[0])
+
+	if len(comments) != 1 {
+		t.Fatalf("Comment() = %v; want 1 comment", comments)
+	}
+
+	tests := []struct {
+		content   string
+		substring string
+	}{
+		{content: stories[0].PostTitle, substring: "Happy 20th birthday"},
+		{content: comments[0].By, substring: "CSMastermind"},
+		{content: article, substring: "Make something people want"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.substring, func(t *testing.T) {
+			if !strings.Contains(tt.content, tt.substring) {
+				t.Errorf("strings.Contains(%s, %s) = false; want true", tt.content, tt.substring)
+			}
+		})
+	}
+
+	if _, err := h.Story(context.Background(), NewFeed); err == nil {
+		t.Errorf("Story(NewFeed) returned no error for a feed that was not synced")
+	}
+
+	if _, err := h.Search(context.Background(), "yc"); err == nil {
+		t.Errorf("Search() returned no error without network")
+	}
+}
@@ -0,0 +1,41 @@
+package hackernews
+
+import "slices"
+
+// Thread grows a comment tree from comments received in any order, as long as
+// a parent comes before its replies, which is how CommentStream sends them.
+type Thread struct {
+	Comments []*CommentNode
+	kids     []int // Order of the top-level comments
+}
+
+func NewThread(story Story) *Thread {
+	return &Thread{
+		Comments: []*CommentNode{},
+		kids:     story.Kids,
+	}
+}
+
+// Insert places node among its siblings in the HN order.
+func (t *Thread) Insert(node *CommentNode) {
+	siblings := &t.Comments
+	kids := t.kids
+
+	if node.Parent != nil {
+		siblings = &node.Parent.Children
+		kids = node.Parent.Kids
+	}
+
+	rank := slices.Index(kids, node.ID)
+	at := len(*siblings)
+
+	for i, sibling := range *siblings {
+		if slices.Index(kids, sibling.ID) > rank {
+			at = i
+
+			break
+		}
+	}
+
+	*siblings = slices.Insert(*siblings, at, node)
+}
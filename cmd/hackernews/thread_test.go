@@ -0,0 +1,73 @@
+package hackernews
+
+import "testing"
+
+func TestThread(t *testing.T) {
+	story := Story{
+		Rank:       0,
+		ID:         43332658,
+		By:         "btilly",
+		PostTitle:  "Happy 20th birthday, Y Combinator",
+		URL:        "ycombinator.com",
+		URLHost:    "ycombinator.com",
+		Time:       1741702475,
+		TimeAgo:    "x days ago",
+		Kids:       []int{1, 2, 3},
+		Score:      1421,
+		NumComment: 5,
+		WebURL:     "",
+	}
+
+	node := func(id int, parent *CommentNode, kids []int) *CommentNode {
+		return &CommentNode{
+			ID:       id,
+			By:       "pg",
+			Text:     "",
+			Time:     0,
+			TimeAgo:  "",
+			Depth:    0,
+			Dead:     false,
+			Deleted:  false,
+			Failed:   false,
+			Kids:     kids,
+			Parent:   parent,
+			Children: []*CommentNode{},
+		}
+	}
+
+	first := node(1, nil, []int{11, 12})
+	third := node(3, nil, []int{})
+	second := node(2, nil, []int{})
+	secondReply := node(12, first, []int{})
+	firstReply := node(11, first, []int{})
+
+	thread := NewThread(story)
+
+	// Received as the fetches complete, not in the HN order
+	for _, n := range []*CommentNode{third, first, secondReply, second, firstReply} {
+		thread.Insert(n)
+	}
+
+	tests := []struct {
+		name     string
+		nodes    []*CommentNode
+		expected []int
+	}{
+		{name: "Top-level comments", nodes: thread.Comments, expected: []int{1, 2, 3}},
+		{name: "Replies", nodes: first.Children, expected: []int{11, 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.nodes) != len(tt.expected) {
+				t.Fatalf("len(nodes) = %v; want %v", len(tt.nodes), len(tt.expected))
+			}
+
+			for i, n := range tt.nodes {
+				if n.ID != tt.expected[i] {
+					t.Errorf("nodes[%d].ID = %v; want %v", i, n.ID, tt.expected[i])
+				}
+			}
+		})
+	}
+}
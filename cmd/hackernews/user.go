@@ -0,0 +1,178 @@
+package hackernews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+var errUser = errors.New("no such user")
+
+// maxThreadDepth bounds the parents followed from a comment up to its story.
+const maxThreadDepth = 100
+
+// User is an HN profile, About is already markdown.
+type User struct {
+	ID          string       `json:"id"`
+	Karma       int          `json:"karma"`
+	Created     int64        `json:"created"`
+	CreatedAgo  string       `json:"-"`
+	About       string       `json:"about"`
+	Submitted   []int        `json:"submitted"` // Most recent first, stories and comments alike
+	Submissions []Submission `json:"-"`         // The most recent ones, a page of them at most
+}
+
+// Submission is a story or a comment a user posted.
+type Submission struct {
+	Story   Story        // The story submitted, or the one the comment belongs to
+	Comment *CommentNode // The comment submitted, nil for a story
+}
+
+// item holds what tells a story from a comment, and a comment from its story.
+type item struct {
+	Type    string `json:"type"`
+	Parent  int    `json:"parent"`
+	Dead    bool   `json:"dead"`
+	Deleted bool   `json:"deleted"`
+}
+
+// User fetches the profile of userID along with a page of its most recent submissions.
+func (h *HackerNews) User(ctx context.Context, userID string) (User, error) {
+	var user User
+
+	if err := h.fetch(ctx, userKind, userID, fmt.Sprintf(h.urlUser, url.PathEscape(userID)), &user); err != nil {
+		return user, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	// The HN API answers null for an unknown user
+	if user.ID == "" {
+		return user, fmt.Errorf("%w: %s", errUser, userID)
+	}
+
+	var err error
+
+	user.About, err = htmltomarkdown.ConvertString(user.About)
+	if err != nil {
+		return user, fmt.Errorf("error rendering user: %w", err)
+	}
+
+	user.CreatedAgo = TimeAgo(time.Unix(user.Created, 0))
+
+	user.Submissions, err = h.fetchSubmissions(ctx, user.Submitted[:min(h.numStory, len(user.Submitted))])
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (h *HackerNews) fetchSubmissions(ctx context.Context, itemIDs []int) ([]Submission, error) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	submissions := make([]*Submission, len(itemIDs))
+
+	for i, itemID := range itemIDs {
+		g.Go(func() error {
+			submission, err := h.fetchSubmission(ctx, itemID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+
+				// One submission should not fail the whole profile
+				return nil
+			}
+
+			submissions[i] = submission
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return []Submission{}, fmt.Errorf("error fetching submission: %w", err)
+	}
+
+	found := []Submission{}
+
+	for _, submission := range submissions {
+		if submission != nil {
+			submission.Story.Rank = len(found)
+			found = append(found, *submission)
+		}
+	}
+
+	return found, nil
+}
+
+// fetchSubmission returns nil for an item not worth listing, deleted or a poll option.
+func (h *HackerNews) fetchSubmission(ctx context.Context, itemID int) (*Submission, error) {
+	var it item
+
+	if err := h.fetch(ctx, itemKind, strconv.Itoa(itemID), fmt.Sprintf(h.urlItem, itemID), &it); err != nil {
+		return nil, fmt.Errorf("error fetching submission: %w", err)
+	}
+
+	switch {
+	case it.Dead || it.Deleted || it.Type == "pollopt":
+		return nil, nil //nolint:nilnil // Nothing to list, and no error
+	case it.Type != "comment":
+		story, err := h.fetchStory(ctx, itemID, 0, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Submission{Story: story, Comment: nil}, nil
+	}
+
+	comment, err := h.fetchComment(ctx, itemID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Up the thread to the story
+	storyID := it.Parent
+
+	for range maxThreadDepth {
+		var parent item
+
+		if err := h.fetch(ctx, itemKind, strconv.Itoa(storyID), fmt.Sprintf(h.urlItem, storyID), &parent); err != nil {
+			return nil, fmt.Errorf("error fetching submission: %w", err)
+		}
+
+		if parent.Type != "comment" {
+			break
+		}
+
+		storyID = parent.Parent
+	}
+
+	story, err := h.fetchStory(ctx, storyID, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Submission{
+		Story: story,
+		Comment: &CommentNode{
+			ID:       comment.ID,
+			By:       comment.By,
+			Text:     comment.Text,
+			Time:     comment.Time,
+			TimeAgo:  TimeAgo(time.Unix(comment.Time, 0)),
+			Depth:    0,
+			Dead:     comment.Dead,
+			Deleted:  comment.Deleted,
+			Failed:   false,
+			Kids:     comment.Kids,
+			Parent:   nil,
+			Children: []*CommentNode{},
+		},
+	}, nil
+}
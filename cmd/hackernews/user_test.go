@@ -0,0 +1,83 @@
+package hackernews
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUser(t *testing.T) {
+	items := map[string]string{
+		"/v0/user/pg.json": `{"id":"pg","karma":157236,"created":1160418092,` +
+			`"about":"Bug fixer.<p><a href=\"https://paulgraham.com\">paulgraham.com</a>","submitted":[2,3,4,1,5]}`,
+		"/v0/item/1.json": `{"id":1,"type":"story","by":"pg","title":"Y Combinator",` +
+			`"url":"https://ycombinator.com","time":1160418111}`,
+		"/v0/item/2.json": `{"id":2,"type":"comment","by":"pg","text":"Reply <i>here</i>","parent":5,"time":1160418200}`,
+		"/v0/item/3.json": `{"id":3,"type":"comment","deleted":true,"parent":1}`,
+		"/v0/item/4.json": `{"id":4,"type":"pollopt","by":"pg","text":"Yes","poll":1}`,
+		"/v0/item/5.json": `{"id":5,"type":"comment","by":"sama","text":"Question","parent":1,"time":1160418150}`,
+	}
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := items[r.URL.Path]
+		if !ok {
+			data = "null"
+		}
+
+		_, _ = w.Write([]byte(data))
+	}))
+	defer mockServer.Close()
+
+	h := NewHackerNews(mockServer.URL+"/v0/%sstories.json",
+		mockServer.URL+"/v0/item/%d.json", mockServer.URL+"/item?id=%d",
+		mockServer.URL+"/api/v1/search?tags=story&query=%s",
+		mockServer.URL+"/v0/updates.json", mockServer.URL+"/v0/user/%s.json", 4, nil)
+
+	user, err := h.User(context.Background(), "pg")
+	if err != nil {
+		t.Fatalf("User() error = %v", err)
+	}
+
+	if user.Karma != 157236 || !strings.Contains(user.About, "[paulgraham.com](https://paulgraham.com)") {
+		t.Errorf("User() = %+v; want the karma, and the about as markdown", user)
+	}
+
+	// Only the first page of submitted is fetched, the deleted comment and the poll option are left out
+	if len(user.Submissions) != 2 {
+		t.Fatalf("User() has %d submissions; want %d", len(user.Submissions), 2)
+	}
+
+	tests := []struct {
+		name            string
+		submission      Submission
+		expectedStoryID int
+		expectedComment string
+	}{
+		{"comment deep in a thread belongs to its story", user.Submissions[0], 1, "Reply *here*"},
+		{"story", user.Submissions[1], 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.submission.Story.ID != tt.expectedStoryID || tt.submission.Story.PostTitle != "Y Combinator" {
+				t.Errorf("submission of story %d; want story %d", tt.submission.Story.ID, tt.expectedStoryID)
+			}
+
+			comment := ""
+			if tt.submission.Comment != nil {
+				comment = tt.submission.Comment.Text
+			}
+
+			if comment != tt.expectedComment {
+				t.Errorf("submission comment = %q; want %q", comment, tt.expectedComment)
+			}
+		})
+	}
+
+	if _, err := h.User(context.Background(), "nobody"); !errors.Is(err, errUser) {
+		t.Errorf("User() of an unknown user = %v; want %v", err, errUser)
+	}
+}
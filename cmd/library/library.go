@@ -0,0 +1,230 @@
+package library
+
+import (
+	"chamot/cmd/hackernews"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Visit is what a story looked like the last time it was opened.
+type Visit struct {
+	NumComment int   `json:"numComment"`
+	Time       int64 `json:"time"` // Unix time, as the HN API gives, zero if the comments were never opened
+}
+
+// bookmark is a story as it was bookmarked, kept whole to outlive the feeds.
+// The fields the HN API does not give are saved along.
+type bookmark struct {
+	hackernews.Story
+	ID      int    `json:"id"`
+	URLHost string `json:"urlHost"`
+	WebURL  string `json:"webUrl"`
+}
+
+type content struct {
+	Visits    map[int]Visit `json:"visits"`
+	Bookmarks []bookmark    `json:"bookmarks"` // Most recent first
+}
+
+// Library remembers the stories read and bookmarked, in one JSON file.
+// A nil Library is valid and remembers nothing.
+type Library struct {
+	path    string
+	content content
+	mutex   sync.Mutex
+}
+
+// NewLibrary reads the library saved at path, a missing file is an empty library.
+func NewLibrary(path string) (*Library, error) {
+	l := &Library{
+		path:    path,
+		content: content{Visits: map[int]Visit{}, Bookmarks: []bookmark{}},
+		mutex:   sync.Mutex{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading library: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &l.content); err != nil {
+		return nil, fmt.Errorf("error decoding library: %w", err)
+	}
+
+	if l.content.Visits == nil {
+		l.content.Visits = map[int]Visit{}
+	}
+
+	if l.content.Bookmarks == nil {
+		l.content.Bookmarks = []bookmark{}
+	}
+
+	return l, nil
+}
+
+// Visit tells when the story was last opened, and how many comments it had then.
+func (l *Library) Visit(storyID int) (Visit, bool) {
+	if l == nil {
+		return Visit{NumComment: 0, Time: 0}, false
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	visit, ok := l.content.Visits[storyID]
+
+	return visit, ok
+}
+
+// MarkVisited remembers the story as opened at t with numComment comments.
+func (l *Library) MarkVisited(storyID int, numComment int, t time.Time) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.content.Visits[storyID] = Visit{NumComment: numComment, Time: t.Unix()}
+
+	return l.save()
+}
+
+// MarkRead remembers the story as read without its comments, a previous visit is kept as it is. A story
+// never visited is recorded with numComment comments and no time, none of them is new to a reader who
+// never opened them.
+func (l *Library) MarkRead(storyID int, numComment int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, ok := l.content.Visits[storyID]; ok {
+		return nil
+	}
+
+	l.content.Visits[storyID] = Visit{NumComment: numComment, Time: 0}
+
+	return l.save()
+}
+
+// Bookmarked tells whether the story is bookmarked.
+func (l *Library) Bookmarked(storyID int) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.bookmark(storyID) >= 0
+}
+
+// Bookmarks returns the stories bookmarked, the most recent first and ranked so.
+func (l *Library) Bookmarks() []hackernews.Story {
+	stories := []hackernews.Story{}
+
+	if l == nil {
+		return stories
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for rank, b := range l.content.Bookmarks {
+		story := b.Story
+		story.Rank = rank
+		story.ID = b.ID
+		story.URLHost = b.URLHost
+		story.WebURL = b.WebURL
+		story.TimeAgo = hackernews.TimeAgo(time.Unix(int64(story.Time), 0))
+		stories = append(stories, story)
+	}
+
+	return stories
+}
+
+// ToggleBookmark bookmarks the story, or removes its bookmark, and tells whether it is bookmarked now.
+func (l *Library) ToggleBookmark(story hackernews.Story) (bool, error) {
+	if l == nil {
+		return false, nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	previous := l.content.Bookmarks
+
+	i := l.bookmark(story.ID)
+	if i >= 0 {
+		l.content.Bookmarks = slices.Delete(slices.Clone(previous), i, i+1)
+	} else {
+		l.content.Bookmarks = slices.Insert(slices.Clone(previous), 0,
+			bookmark{Story: story, ID: story.ID, URLHost: story.URLHost, WebURL: story.WebURL})
+	}
+
+	// An unsaved toggle is undone, so that it can be tried again
+	if err := l.save(); err != nil {
+		l.content.Bookmarks = previous
+
+		return i >= 0, err
+	}
+
+	return i < 0, nil
+}
+
+func (l *Library) bookmark(storyID int) int {
+	return slices.IndexFunc(l.content.Bookmarks, func(b bookmark) bool {
+		return b.ID == storyID
+	})
+}
+
+func (l *Library) save() error {
+	data, err := json.Marshal(l.content)
+	if err != nil {
+		return fmt.Errorf("error encoding library: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return fmt.Errorf("error creating library dir: %w", err)
+	}
+
+	// Write then rename so that a crash never leaves a partial library
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating library: %w", err)
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("error writing library: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing library: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("error writing library: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package library
+
+import (
+	"chamot/cmd/hackernews"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLibrary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chamot", "library.json")
+
+	l, err := NewLibrary(path)
+	if err != nil {
+		t.Fatalf("NewLibrary() without a file = %v", err)
+	}
+
+	if err := l.MarkVisited(43332658, 3, time.Unix(1741746022, 0)); err != nil {
+		t.Fatalf("MarkVisited() = %v", err)
+	}
+
+	// Reading the article keeps the visit of the comments, and records a story never visited
+	if err := l.MarkRead(43332658, 5); err != nil {
+		t.Fatalf("MarkRead() = %v", err)
+	}
+
+	if err := l.MarkRead(16582136, 436); err != nil {
+		t.Fatalf("MarkRead() = %v", err)
+	}
+
+	// A library read again from disk remembers the same
+	reloaded, err := NewLibrary(path)
+	if err != nil {
+		t.Fatalf("NewLibrary() = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		library       *Library
+		storyID       int
+		expectedOK    bool
+		expectedVisit Visit
+	}{
+		{"visited", l, 43332658, true, Visit{NumComment: 3, Time: 1741746022}},
+		{"visited, read from disk", reloaded, 43332658, true, Visit{NumComment: 3, Time: 1741746022}},
+		{"article read", reloaded, 16582136, true, Visit{NumComment: 436, Time: 0}},
+		{"never visited", reloaded, 1, false, Visit{NumComment: 0, Time: 0}},
+		{"nil library", nil, 43332658, false, Visit{NumComment: 0, Time: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visit, ok := tt.library.Visit(tt.storyID)
+
+			if ok != tt.expectedOK || visit != tt.expectedVisit {
+				t.Errorf("Visit(%d) = %v, %v; want %v, %v", tt.storyID, visit, ok, tt.expectedVisit, tt.expectedOK)
+			}
+		})
+	}
+}
+
+func TestBookmark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "library.json")
+
+	l, err := NewLibrary(path)
+	if err != nil {
+		t.Fatalf("NewLibrary() = %v", err)
+	}
+
+	first := hackernews.Story{ID: 1, PostTitle: "First", WebURL: "item?id=1"}   //nolint:exhaustruct // Not used
+	second := hackernews.Story{ID: 2, PostTitle: "Second", WebURL: "item?id=2"} //nolint:exhaustruct // Not used
+
+	tests := []struct {
+		name               string
+		story              hackernews.Story
+		expectedBookmarked bool
+		expectedIDs        []int
+	}{
+		{"bookmark", first, true, []int{1}},
+		{"most recent first", second, true, []int{2, 1}},
+		{"remove the bookmark", first, false, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookmarked, err := l.ToggleBookmark(tt.story)
+			if err != nil {
+				t.Fatalf("ToggleBookmark() = %v", err)
+			}
+
+			if bookmarked != tt.expectedBookmarked || l.Bookmarked(tt.story.ID) != tt.expectedBookmarked {
+				t.Errorf("ToggleBookmark(%d) = %v; want %v", tt.story.ID, bookmarked, tt.expectedBookmarked)
+			}
+
+			// A library read again from disk has the same bookmarks
+			reloaded, err := NewLibrary(path)
+			if err != nil {
+				t.Fatalf("NewLibrary() = %v", err)
+			}
+
+			for _, library := range []*Library{l, reloaded} {
+				ids := []int{}
+
+				for _, story := range library.Bookmarks() {
+					ids = append(ids, story.ID)
+
+					if story.WebURL == "" {
+						t.Errorf("story %d lost its web URL", story.ID)
+					}
+				}
+
+				if !slices.Equal(ids, tt.expectedIDs) {
+					t.Errorf("Bookmarks() = %v; want %v", ids, tt.expectedIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestLibraryCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "library.json")
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	if _, err := NewLibrary(path); err == nil {
+		t.Errorf("NewLibrary() read a corrupted library")
+	}
+}
@@ -0,0 +1,270 @@
+package ollama
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// charsPerToken is how many ASCII characters make a token on average, other characters
+	// are counted as a token each, which is about right for CJK and on the safe side otherwise.
+	charsPerToken = 4
+	// messageTokens frames each message, its role and delimiters.
+	messageTokens = 4
+	// maxAnswerTokens bounds the room kept for the answer, large contexts go to the prompt.
+	maxAnswerTokens = 1024
+	// trimMarker replaces what was trimmed off a message too long for the context.
+	trimMarker = "[… trimmed to fit the context …]"
+)
+
+// budget splits the context window of the model, in tokens, between the prompt and the answer.
+type budget struct {
+	numCtx int // Tokens of the context window
+	answer int // Tokens kept free for the answer
+}
+
+func newBudget(numCtx int) budget {
+	return budget{numCtx: numCtx, answer: min(numCtx/4, maxAnswerTokens)}
+}
+
+// prompt tells how many tokens the messages sent can take.
+func (b budget) prompt() int {
+	return b.numCtx - b.answer
+}
+
+// fit drops the oldest turns of the history, the system prompts aside, until messages fit in the
+// prompt. If they still do not, the longest system prompts are trimmed to the same length, then
+// the last message if it does not fit alone. Chat condenses the system prompts before, what is
+// trimmed here is what the summaries could not shrink.
+func (b budget) fit(messages []Message) []Message {
+	fitted := b.drop(messages)
+
+	if estimateMessages(fitted) > b.prompt() {
+		length := b.systemLength(fitted)
+
+		for i := range fitted[:max(0, len(fitted)-1)] {
+			if fitted[i].Role == RoleSystem {
+				fitted[i].Content = trim(fitted[i].Content, length)
+			}
+		}
+	}
+
+	if last := len(fitted) - 1; last >= 0 {
+		rest := estimateMessages(fitted[:last]) + messageTokens
+		fitted[last].Content = trim(fitted[last].Content, b.prompt()-rest)
+	}
+
+	return fitted
+}
+
+// drop returns messages without their oldest turns, the system prompts aside, until they fit in the prompt
+// or only the last turn is left.
+func (b budget) drop(messages []Message) []Message {
+	dropped := slices.Clone(messages)
+
+	for estimateMessages(dropped) > b.prompt() {
+		oldest := slices.IndexFunc(dropped[:max(0, len(dropped)-1)], func(message Message) bool {
+			return message.Role != RoleSystem
+		})
+		if oldest < 0 {
+			break
+		}
+
+		dropped = slices.Delete(dropped, oldest, oldest+1)
+	}
+
+	return dropped
+}
+
+// systemLength tells the common length the system prompts longer than it are to be shortened to, the longest
+// one that leaves room for the other messages. The last message is left to fit, even if it is a system prompt.
+func (b budget) systemLength(messages []Message) int {
+	room := b.prompt()
+	others := 0
+	sizes := []int{}
+
+	for i, message := range messages {
+		room -= messageTokens
+
+		if message.Role == RoleSystem && i < len(messages)-1 {
+			sizes = append(sizes, estimateTokens(message.Content))
+		} else {
+			others += estimateTokens(message.Content)
+		}
+	}
+
+	// A last message too long is trimmed too, the system prompts keep half of the prompt at least
+	room = max(room-others, room/2)
+
+	// Shorter prompts are kept whole, the longer ones share what is left
+	slices.Sort(sizes)
+
+	length := 0
+
+	for i, size := range sizes {
+		if share := max(0, room) / (len(sizes) - i); size > share {
+			length = share
+
+			break
+		}
+
+		room -= size
+		length = size
+	}
+
+	return length
+}
+
+// estimateTokens tells about how many tokens text takes, without the tokenizer of the model at hand.
+func estimateTokens(text string) int {
+	ascii, other := 0, 0
+
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+
+	return (ascii+charsPerToken-1)/charsPerToken + other
+}
+
+func estimateMessages(messages []Message) int {
+	total := 0
+	for _, message := range messages {
+		total += messageTokens + estimateTokens(message.Content)
+	}
+
+	return total
+}
+
+// trim keeps the first paragraphs of text that fit in tokens, then the first sentences of the next one,
+// and ends with trimMarker. Text that fits is left as it is.
+func trim(text string, tokens int) string {
+	const gap = "\n\n"
+
+	if estimateTokens(text) <= tokens {
+		return text
+	}
+
+	room := tokens - estimateTokens(gap+trimMarker)
+	kept := []string{}
+
+	for _, paragraph := range strings.Split(text, gap) {
+		cost := estimateTokens(paragraph) + estimateTokens(gap)
+		if cost <= room {
+			kept = append(kept, paragraph)
+			room -= cost
+
+			continue
+		}
+
+		partial := keepFirst(sentences(paragraph), room-estimateTokens(gap))
+
+		// Words are better than nothing, a few words cut after a paragraph are not
+		if partial == "" && len(kept) == 0 {
+			partial = keepFirst(strings.SplitAfter(paragraph, " "), room-estimateTokens(gap))
+		}
+
+		if partial != "" {
+			kept = append(kept, partial)
+		}
+
+		break
+	}
+
+	return strings.Join(append(kept, trimMarker), gap)
+}
+
+// keepFirst joins the first pieces that fit in tokens.
+func keepFirst(pieces []string, tokens int) string {
+	kept := strings.Builder{}
+	used := 0
+
+	for _, piece := range pieces {
+		used += estimateTokens(piece)
+		if used > tokens {
+			break
+		}
+
+		kept.WriteString(piece)
+	}
+
+	return strings.TrimSpace(kept.String())
+}
+
+// sentences splits paragraph after each end of sentence followed by a space, the space included.
+func sentences(paragraph string) []string {
+	split := []string{}
+	start := 0
+	ended := false
+
+	for i, r := range paragraph {
+		switch {
+		case r == '.' || r == '!' || r == '?':
+			ended = true
+		case ended && unicode.IsSpace(r):
+			split = append(split, paragraph[start:i+utf8.RuneLen(r)])
+			start = i + utf8.RuneLen(r)
+			ended = false
+		default:
+			ended = false
+		}
+	}
+
+	if start < len(paragraph) {
+		split = append(split, paragraph[start:])
+	}
+
+	return split
+}
+
+// split cuts text into parts of at most tokens each, between paragraphs, or between the sentences
+// and then the words of a paragraph too long. A single word too long is a part on its own.
+func split(text string, tokens int) []string {
+	return pack(strings.Split(text, "\n\n"), "\n\n", tokens, func(paragraph string) []string {
+		return pack(sentences(paragraph), "", tokens, func(sentence string) []string {
+			return pack(strings.SplitAfter(sentence, " "), "", tokens, nil)
+		})
+	})
+}
+
+// pack joins pieces with sep into parts of at most tokens each, the pieces too long are cut first.
+func pack(pieces []string, sep string, tokens int, cut func(string) []string) []string {
+	parts := []string{}
+	part := ""
+
+	flush := func() {
+		if strings.TrimSpace(part) != "" {
+			parts = append(parts, strings.TrimSpace(part))
+		}
+
+		part = ""
+	}
+
+	for _, piece := range pieces {
+		if cut != nil && estimateTokens(piece) > tokens {
+			flush()
+			parts = append(parts, cut(piece)...)
+
+			continue
+		}
+
+		if part != "" && estimateTokens(part+sep+piece) > tokens {
+			flush()
+		}
+
+		if part == "" {
+			part = piece
+		} else {
+			part += sep + piece
+		}
+	}
+
+	flush()
+
+	return parts
+}
@@ -0,0 +1,214 @@
+package ollama
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"Hi", 1},
+		{"Hello, world!", 4},
+		{"こんにちは", 5},
+		{"Café crème", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if tokens := estimateTokens(tt.text); tokens != tt.expected {
+				t.Errorf("estimateTokens(%q) = %d; want %d", tt.text, tokens, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	article := "Hacker News turns twenty.\n\n" +
+		"It started as a side project. It is now read by millions! Who knew? Not its founders.\n\n" +
+		"The end."
+
+	tests := []struct {
+		name     string
+		text     string
+		tokens   int
+		expected string
+	}{
+		{
+			name:     "text that fits",
+			text:     article,
+			tokens:   100,
+			expected: article,
+		},
+		{
+			name:     "whole paragraphs",
+			text:     article,
+			tokens:   20,
+			expected: "Hacker News turns twenty.\n\n" + trimMarker,
+		},
+		{
+			name:   "first sentences of the next paragraph",
+			text:   article,
+			tokens: 28,
+			expected: "Hacker News turns twenty.\n\n" +
+				"It started as a side project.\n\n" + trimMarker,
+		},
+		{
+			name:     "first words of a long sentence",
+			text:     "Hacker News is a social news website focusing on computer science",
+			tokens:   16,
+			expected: "Hacker News is\n\n" + trimMarker,
+		},
+		{
+			name:     "runes left whole",
+			text:     "ハッカー ニュース は 二十 歳 に なり まし た",
+			tokens:   16,
+			expected: "ハッカー\n\n" + trimMarker,
+		},
+		{
+			name:     "no room at all",
+			text:     article,
+			tokens:   0,
+			expected: trimMarker,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed := trim(tt.text, tt.tokens)
+
+			if trimmed != tt.expected {
+				t.Errorf("trim() = %q; want %q", trimmed, tt.expected)
+			}
+
+			if !utf8.ValidString(trimmed) {
+				t.Errorf("trim() split a rune: %q", trimmed)
+			}
+
+			if trimmed != trimMarker && estimateTokens(trimmed) > tt.tokens {
+				t.Errorf("trim() = %d tokens; want at most %d", estimateTokens(trimmed), tt.tokens)
+			}
+		})
+	}
+}
+
+func TestBudgetFit(t *testing.T) {
+	system := Message{Role: RoleSystem, Content: "Be brief."}
+	first := Message{Role: RoleUser, Content: "What is Hacker News?"}
+	answer := Message{Role: RoleAssistant, Content: "A news site."}
+	last := Message{Role: RoleUser, Content: "Who runs it?"}
+	article := Message{Role: RoleUser, Content: "Summarize this article.\n\n" + strings.Repeat("Some news. ", 200)}
+	question := Message{Role: RoleUser, Content: "What about pricing?"}
+
+	paragraphs := func(format string) Message {
+		lines := []string{}
+		for i := 1; i <= 10; i++ {
+			lines = append(lines, fmt.Sprintf(format, i))
+		}
+
+		return Message{Role: RoleSystem, Content: strings.Join(lines, "\n\n")}
+	}
+
+	tests := []struct {
+		name     string
+		numCtx   int
+		messages []Message
+		expected []Message
+	}{
+		{
+			name:     "history fits",
+			numCtx:   100,
+			messages: []Message{system, first, answer, last},
+			expected: []Message{system, first, answer, last},
+		},
+		{
+			name:     "oldest turns dropped, system prompt kept",
+			numCtx:   36,
+			messages: []Message{system, first, answer, last},
+			expected: []Message{system, answer, last},
+		},
+		{
+			name:     "last message trimmed, room kept for the answer",
+			numCtx:   40,
+			messages: []Message{system, article},
+			expected: []Message{system, {Role: RoleUser, Content: "Summarize this article.\n\n" + trimMarker}},
+		},
+		{
+			name:     "longest system prompts trimmed to the same length",
+			numCtx:   100,
+			messages: []Message{system, paragraphs("Article paragraph %d."), paragraphs("- pg: comment %d."), question},
+			expected: []Message{
+				system,
+				{Role: RoleSystem, Content: "Article paragraph 1.\n\nArticle paragraph 2.\n\n" + trimMarker},
+				{Role: RoleSystem, Content: "- pg: comment 1.\n\n- pg: comment 2.\n\n- pg: comment 3.\n\n" + trimMarker},
+				question,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fitted := newBudget(tt.numCtx).fit(tt.messages)
+
+			if !slices.Equal(fitted, tt.expected) {
+				t.Errorf("fit() = %q; want %q", fitted, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		tokens   int
+		expected []string
+	}{
+		{
+			name:     "text that fits",
+			text:     "Hacker News turns twenty.\n\nThe end.",
+			tokens:   100,
+			expected: []string{"Hacker News turns twenty.\n\nThe end."},
+		},
+		{
+			name:     "between paragraphs",
+			text:     "Hacker News turns twenty.\n\nIt started as a side project.\n\nThe end.",
+			tokens:   10,
+			expected: []string{"Hacker News turns twenty.", "It started as a side project.\n\nThe end."},
+		},
+		{
+			name:     "between sentences of a long paragraph",
+			text:     "It started as a side project. It is now read by millions! Who knew?",
+			tokens:   10,
+			expected: []string{"It started as a side project.", "It is now read by millions! Who knew?"},
+		},
+		{
+			name:     "between words of a long sentence",
+			text:     "Hacker News is a social news website focusing on computer science",
+			tokens:   10,
+			expected: []string{"Hacker News is a social news website", "focusing on computer science"},
+		},
+		{
+			name:     "empty paragraphs left out",
+			text:     "\n\nThe end.\n\n\n\n",
+			tokens:   10,
+			expected: []string{"The end."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := split(tt.text, tt.tokens)
+
+			if !slices.Equal(parts, tt.expected) {
+				t.Errorf("split() = %q; want %q", parts, tt.expected)
+			}
+		})
+	}
+}
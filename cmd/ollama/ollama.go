@@ -3,36 +3,60 @@ package ollama
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+)
+
+var errStatus = errors.New("unexpected status")
+
+// Roles of the messages of a conversation.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
 )
 
 type API interface {
-	Chat(in chan string, out chan Response, stop chan bool) error
+	Chat(ctx context.Context, messages []Message, progress chan<- Progress, out chan<- Response) error
+	Summarize(ctx context.Context, messages []Message, text string, progress chan<- Progress,
+		out chan<- Response) error
 }
 
 type Ollama struct {
-	url    string
-	model  string
-	numCtx int
-	client *http.Client
+	url       string
+	model     string
+	numCtx    int
+	budget    budget
+	client    *http.Client
+	mutex     sync.Mutex
+	condensed map[string]string // Summaries of the system prompts of the last conversation condensed, by content
 }
 
 type Options struct {
 	NumCtx int `json:"num_ctx"` //nolint:tagliatelle // Well it's Ollama
 }
 
+// Message is one turn of a conversation, the system prompt included.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
 type Request struct {
-	Model   string  `json:"model"`
-	Prompt  string  `json:"prompt"`
-	Stream  bool    `json:"stream"`
-	Options Options `json:"options"`
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Options  Options   `json:"options"`
 }
 
+// Response is a piece of the answer, Message.Content is to be appended to the previous ones.
 type Response struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
 }
 
 func NewOllama(url string, model string, numCtx int) *Ollama {
@@ -40,63 +64,87 @@ func NewOllama(url string, model string, numCtx int) *Ollama {
 		url:    url,
 		model:  model,
 		numCtx: numCtx,
+		budget: newBudget(numCtx),
 		client: &http.Client{
 			Transport:     nil,
 			CheckRedirect: nil,
 			Jar:           nil,
 			Timeout:       0,
 		},
+		mutex:     sync.Mutex{},
+		condensed: map[string]string{},
 	}
 }
 
-func (o *Ollama) Chat(in chan string, out chan Response, stop chan bool) error {
-	for {
-		message := <-in
+// Chat streams the answer to the last of messages into out, and closes out at the end.
+// The messages before are the history of the conversation, system prompts too long for the
+// context are summarized first, and each part summarized is told to progress. progress is closed
+// before the answer. Cancelling ctx aborts the request.
+func (o *Ollama) Chat(ctx context.Context, messages []Message, progress chan<- Progress, out chan<- Response) error {
+	messages, err := o.condense(ctx, messages, progress)
+	close(progress)
 
-		if len(message) > o.numCtx {
-			half := o.numCtx / 2
-			message = message[:half] + "\n...\n" + message[len(message)-half:]
-		}
+	if err != nil {
+		close(out)
 
-		body, err := json.Marshal(Request{
-			Model:   o.model,
-			Prompt:  message,
-			Stream:  true,
-			Options: Options{NumCtx: o.numCtx},
-		})
-		if err != nil {
-			return fmt.Errorf("error marshaling: %w", err)
-		}
+		return err
+	}
+
+	return o.stream(ctx, messages, out)
+}
+
+// stream streams the answer to the last of messages into out as they are, and closes out at the end.
+func (o *Ollama) stream(ctx context.Context, messages []Message, out chan<- Response) error {
+	defer close(out)
+
+	body, err := json.Marshal(Request{
+		Model:    o.model,
+		Messages: o.budget.fit(messages),
+		Stream:   true,
+		Options:  Options{NumCtx: o.numCtx},
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling: %w", err)
+	}
 
-		resp, err := o.client.Post(o.url, "application/json", bytes.NewBuffer(body))
-		if err != nil {
-			return fmt.Errorf("error sending: %w", err)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("error sending: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error receiving: %w: %s", errStatus, resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+		var ollamaResp Response
+		if err := json.Unmarshal(scanner.Bytes(), &ollamaResp); err != nil {
+			continue
 		}
 
-		defer func() {
-			_ = resp.Body.Close()
-		}()
-
-		scanner := bufio.NewScanner(resp.Body)
-		done := false
-
-		for scanner.Scan() {
-			var ollamaResp Response
-			if err := json.Unmarshal(scanner.Bytes(), &ollamaResp); err == nil {
-				out <- ollamaResp
-			}
-			select {
-			case <-stop:
-				done = true
-			default:
-			}
-
-			if done {
-				_ = resp.Body.Close()
-				out <- Response{Response: "", Done: true}
-
-				break
-			}
+		select {
+		case out <- ollamaResp:
+		case <-ctx.Done():
+			return fmt.Errorf("error receiving: %w", ctx.Err())
 		}
 	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error receiving: %w", err)
+	}
+
+	return nil
 }
@@ -1,27 +1,34 @@
 package ollama
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 	"time"
 )
 
 func TestChat(t *testing.T) {
 	mockResponses := []Response{
-		{Response: "Hi,", Done: false},
-		{Response: "What can I help with?", Done: true},
+		{Message: Message{Role: RoleAssistant, Content: "Hi,"}, Done: false},
+		{Message: Message{Role: RoleAssistant, Content: "What can I help with?"}, Done: true},
 	}
 
+	received := make(chan []Message, 1)
+
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req Request
 		_ = json.NewDecoder(r.Body).Decode(&req)
+		received <- req.Messages
 
 		for _, res := range mockResponses {
 			resBytes, _ := json.Marshal(res)
 			_, _ = w.Write(resBytes)
 			_, _ = w.Write([]byte("\n"))
+			w.(http.Flusher).Flush()
 
 			time.Sleep(10 * time.Millisecond)
 		}
@@ -30,42 +37,54 @@ func TestChat(t *testing.T) {
 
 	ollamaClient := NewOllama(mockServer.URL, "test-model", 100)
 
-	in := make(chan string, 1)
-	out := make(chan Response, len(mockResponses))
-	stop := make(chan bool, 1)
-
-	go func() {
-		_ = ollamaClient.Chat(in, out, stop)
-	}()
-
 	tests := []struct {
 		input            string
 		expectedResponse []Response
-		stop             bool
+		cancel           bool
 	}{
 		{
 			input:            "Hello...",
 			expectedResponse: mockResponses,
-			stop:             false,
+			cancel:           false,
 		},
 		{
 			input:            "Hello and bye",
 			expectedResponse: []Response{mockResponses[0]},
-			stop:             true,
+			cancel:           true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			in <- tt.input
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			out := make(chan Response)
+			errs := make(chan error, 1)
 
-			if tt.stop {
-				stop <- true
+			messages := []Message{
+				{Role: RoleSystem, Content: "Be brief."},
+				{Role: RoleUser, Content: "Hi"},
+				{Role: RoleAssistant, Content: "Hello!"},
+				{Role: RoleUser, Content: tt.input},
 			}
 
+			go func() {
+				errs <- ollamaClient.Chat(ctx, messages, make(chan Progress), out)
+			}()
+
 			receivedResponses := []Response{}
-			for range mockResponses {
-				receivedResponses = append(receivedResponses, <-out)
+
+			for res := range out {
+				receivedResponses = append(receivedResponses, res)
+
+				if tt.cancel {
+					cancel()
+				}
+			}
+
+			if len(receivedResponses) != len(tt.expectedResponse) {
+				t.Fatalf("expected %d responses, got %d", len(tt.expectedResponse), len(receivedResponses))
 			}
 
 			for i, res := range tt.expectedResponse {
@@ -73,6 +92,38 @@ func TestChat(t *testing.T) {
 					t.Errorf("Response %d: expected %v, got %v", i, res, receivedResponses[i])
 				}
 			}
+
+			if sent := <-received; !slices.Equal(sent, messages) {
+				t.Errorf("expected the history %v to be sent, got %v", messages, sent)
+			}
+
+			if err := <-errs; (err != nil) != tt.cancel {
+				t.Errorf("expected error: %v, got %v", tt.cancel, err)
+			}
 		})
 	}
 }
+
+func TestChatStatus(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer mockServer.Close()
+
+	ollamaClient := NewOllama(mockServer.URL, "test-model", 100)
+	out := make(chan Response)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- ollamaClient.Chat(context.Background(), []Message{{Role: RoleUser, Content: "Hi"}},
+			make(chan Progress), out)
+	}()
+
+	for res := range out {
+		t.Errorf("expected no response, got %v", res)
+	}
+
+	if err := <-errs; !errors.Is(err, errStatus) {
+		t.Errorf("expected the status to be an error, got %v", err)
+	}
+}
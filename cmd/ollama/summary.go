@@ -0,0 +1,156 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// partPrompt asks for the summary of one part of a text too long for the context.
+	partPrompt = "Summarize this part of a longer text in a few lines, keeping its key points and facts. " +
+		"Answer with the summary only."
+	// maxPasses bounds how many times summaries are summarized again, in case they do not shrink.
+	maxPasses = 3
+)
+
+// Progress tells how far the summary of a long text is, Done parts out of Total summarized.
+type Progress struct {
+	Done  int
+	Total int
+}
+
+// Summarize streams into out the answer to the last of messages, with text attached to it. A text too long
+// for the context is split into parts summarized one by one, their summaries are attached instead,
+// and each part summarized is told to progress, that of the system prompts condensed as Chat does too.
+// progress is closed once the parts are summarized, out at the end.
+func (o *Ollama) Summarize(ctx context.Context, messages []Message, text string, progress chan<- Progress,
+	out chan<- Response,
+) error {
+	text, err := o.reduce(ctx, text, o.budget.prompt()-estimateMessages(messages), progress)
+	if err != nil {
+		close(progress)
+		close(out)
+
+		return err
+	}
+
+	attached := slices.Clone(messages)
+
+	if last := len(attached) - 1; last >= 0 {
+		attached[last].Content += "\n\n" + text
+	}
+
+	return o.Chat(ctx, attached, progress, out)
+}
+
+// reduce summarizes the parts of text until it fits in room, each part summarized is told to progress.
+func (o *Ollama) reduce(ctx context.Context, text string, room int, progress chan<- Progress) (string, error) {
+	// The room left to a part once the instruction is sent
+	partRoom := o.budget.prompt() - estimateMessages([]Message{{Role: RoleSystem, Content: partPrompt}}) -
+		messageTokens
+
+	for pass := 0; estimateTokens(text) > room && pass < maxPasses; pass++ {
+		parts := split(text, partRoom)
+		summaries := []string{}
+
+		for i, part := range parts {
+			summary, err := o.answer(ctx, []Message{
+				{Role: RoleSystem, Content: partPrompt},
+				{Role: RoleUser, Content: part},
+			})
+			if err != nil {
+				return "", fmt.Errorf("error summarizing part %d of %d: %w", i+1, len(parts), err)
+			}
+
+			summaries = append(summaries, strings.TrimSpace(summary))
+
+			select {
+			case progress <- Progress{Done: i + 1, Total: len(parts)}:
+			case <-ctx.Done():
+				return "", fmt.Errorf("error summarizing: %w", ctx.Err())
+			}
+		}
+
+		text = "Summaries of the parts of the text, in order:\n\n" + strings.Join(summaries, "\n\n")
+	}
+
+	return text, nil
+}
+
+// condense summarizes the system prompts too long for the context, part by part as Summarize does, rather
+// than trimming them. Only the summaries of this conversation are kept, those of the previous stories are let go.
+func (o *Ollama) condense(ctx context.Context, messages []Message, progress chan<- Progress) ([]Message, error) {
+	condensed := o.budget.drop(messages)
+	if estimateMessages(condensed) <= o.budget.prompt() {
+		return messages, nil
+	}
+
+	length := o.budget.systemLength(condensed)
+	kept := map[string]string{}
+
+	for i, message := range condensed[:len(condensed)-1] {
+		if message.Role != RoleSystem || estimateTokens(message.Content) <= length {
+			continue
+		}
+
+		summary, err := o.condenseSystem(ctx, message.Content, length, progress)
+		if err != nil {
+			return messages, err
+		}
+
+		kept[message.Content] = summary
+		condensed[i].Content = summary
+	}
+
+	o.mutex.Lock()
+	o.condensed = kept
+	o.mutex.Unlock()
+
+	return condensed, nil
+}
+
+// condenseSystem summarizes a system prompt into tokens, its first paragraph, which tells what it holds,
+// kept as it is. A prompt is summarized once, as long as its summary still fits.
+func (o *Ollama) condenseSystem(ctx context.Context, prompt string, tokens int, progress chan<- Progress,
+) (string, error) {
+	const gap = "\n\n"
+
+	o.mutex.Lock()
+	summary, ok := o.condensed[prompt]
+	o.mutex.Unlock()
+
+	if ok && estimateTokens(summary) <= tokens {
+		return summary, nil
+	}
+
+	heading, text, found := strings.Cut(prompt, gap)
+	if !found {
+		heading, text = "", prompt
+	}
+
+	text, err := o.reduce(ctx, text, tokens-estimateTokens(heading+gap), progress)
+	if err != nil {
+		return "", fmt.Errorf("error condensing: %w", err)
+	}
+
+	return strings.TrimPrefix(heading+gap+text, gap), nil
+}
+
+// answer returns the whole answer to the last of messages, once streamed.
+func (o *Ollama) answer(ctx context.Context, messages []Message) (string, error) {
+	out := make(chan Response)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- o.stream(ctx, messages, out)
+	}()
+
+	answer := strings.Builder{}
+	for response := range out {
+		answer.WriteString(response.Message.Content)
+	}
+
+	return answer.String(), <-errs
+}
@@ -0,0 +1,228 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	final := make(chan []Message, 1)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		content := "Final summary"
+
+		// A part is answered with its first words
+		if req.Messages[0].Content == partPrompt {
+			content = "About " + strings.Join(strings.Fields(req.Messages[1].Content)[:2], " ")
+		} else {
+			final <- req.Messages
+		}
+
+		resBytes, _ := json.Marshal(Response{Message: Message{Role: RoleAssistant, Content: content}, Done: true})
+		_, _ = w.Write(resBytes)
+		_, _ = w.Write([]byte("\n"))
+	}))
+	defer mockServer.Close()
+
+	ollamaClient := NewOllama(mockServer.URL, "test-model", 100)
+
+	paragraph := func(topic string) string {
+		return topic + " is discussed at length in this paragraph, sentence after sentence. " +
+			"It goes on and on, well beyond what a small context holds."
+	}
+
+	tests := []struct {
+		name             string
+		text             string
+		expectedProgress []Progress
+		expectedAttached string
+	}{
+		{
+			name:             "short text attached as it is",
+			text:             "The end.",
+			expectedProgress: []Progress{},
+			expectedAttached: "The end.",
+		},
+		{
+			name:             "long text summarized part by part",
+			text:             paragraph("Startups") + "\n\n" + paragraph("Funding") + "\n\n" + paragraph("Hiring"),
+			expectedProgress: []Progress{{Done: 1, Total: 3}, {Done: 2, Total: 3}, {Done: 3, Total: 3}},
+			expectedAttached: "Summaries of the parts of the text, in order:\n\n" +
+				"About Startups is\n\nAbout Funding is\n\nAbout Hiring is",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := []Message{
+				{Role: RoleSystem, Content: "Be brief."},
+				{Role: RoleUser, Content: "Summarize this article."},
+			}
+
+			progress := make(chan Progress)
+			out := make(chan Response)
+			errs := make(chan error, 1)
+
+			go func() {
+				errs <- ollamaClient.Summarize(context.Background(), messages, tt.text, progress, out)
+			}()
+
+			receivedProgress := []Progress{}
+			for p := range progress {
+				receivedProgress = append(receivedProgress, p)
+			}
+
+			answer := ""
+			for res := range out {
+				answer += res.Message.Content
+			}
+
+			if err := <-errs; err != nil {
+				t.Fatalf("Summarize() error = %v", err)
+			}
+
+			if !slices.Equal(receivedProgress, tt.expectedProgress) {
+				t.Errorf("progress = %v; want %v", receivedProgress, tt.expectedProgress)
+			}
+
+			if answer != "Final summary" {
+				t.Errorf("answer = %q; want the final summary", answer)
+			}
+
+			sent := <-final
+			expected := "Summarize this article.\n\n" + tt.expectedAttached
+
+			if last := sent[len(sent)-1].Content; last != expected {
+				t.Errorf("final prompt = %q; want %q", last, expected)
+			}
+
+			if messages[1].Content != "Summarize this article." {
+				t.Errorf("Summarize() changed the messages given: %q", messages[1].Content)
+			}
+		})
+	}
+}
+
+func TestChatCondense(t *testing.T) {
+	final := make(chan []Message, 1)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		content := "Final answer"
+
+		// A part is answered with its first words
+		if req.Messages[0].Content == partPrompt {
+			content = "About " + strings.Join(strings.Fields(req.Messages[1].Content)[:2], " ")
+		} else {
+			final <- req.Messages
+		}
+
+		resBytes, _ := json.Marshal(Response{Message: Message{Role: RoleAssistant, Content: content}, Done: true})
+		_, _ = w.Write(resBytes)
+		_, _ = w.Write([]byte("\n"))
+	}))
+	defer mockServer.Close()
+
+	ollamaClient := NewOllama(mockServer.URL, "test-model", 200)
+
+	article := func(topics ...string) string {
+		paragraphs := []string{}
+		for _, topic := range topics {
+			paragraphs = append(paragraphs, topic+" is discussed at length in this paragraph, sentence after sentence. "+
+				"It goes on and on, well beyond what a small context holds.")
+		}
+
+		return "Article:\n\n" + strings.Join(paragraphs, "\n\n")
+	}
+
+	yc := article("Startups", "Funding", "Hiring", "Growth", "Exits", "Failures")
+	other := article("Rust", "Memory", "Safety", "Borrowing", "Lifetimes", "Traits")
+
+	tests := []struct {
+		name             string
+		article          string
+		expectedProgress []Progress
+		expectedArticle  string
+	}{
+		{
+			name:             "long article summarized",
+			article:          yc,
+			expectedProgress: []Progress{{Done: 1, Total: 2}, {Done: 2, Total: 2}},
+			expectedArticle:  "About Startups is\n\nAbout Growth is",
+		},
+		{
+			name:             "same article summarized once",
+			article:          yc,
+			expectedProgress: []Progress{},
+			expectedArticle:  "About Startups is\n\nAbout Growth is",
+		},
+		{
+			name:             "another story summarized",
+			article:          other,
+			expectedProgress: []Progress{{Done: 1, Total: 2}, {Done: 2, Total: 2}},
+			expectedArticle:  "About Rust is\n\nAbout Borrowing is",
+		},
+		{
+			name:             "summary of the previous story let go",
+			article:          yc,
+			expectedProgress: []Progress{{Done: 1, Total: 2}, {Done: 2, Total: 2}},
+			expectedArticle:  "About Startups is\n\nAbout Growth is",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := []Message{
+				{Role: RoleSystem, Content: "Be brief."},
+				{Role: RoleSystem, Content: tt.article},
+				{Role: RoleSystem, Content: "Comments:\n\n- pg: Happy birthday."},
+				{Role: RoleUser, Content: "How did it go?"},
+			}
+
+			progress := make(chan Progress)
+			out := make(chan Response)
+			errs := make(chan error, 1)
+
+			go func() {
+				errs <- ollamaClient.Chat(context.Background(), messages, progress, out)
+			}()
+
+			receivedProgress := []Progress{}
+			for p := range progress {
+				receivedProgress = append(receivedProgress, p)
+			}
+
+			for range out {
+			}
+
+			if err := <-errs; err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+
+			sent := <-final
+
+			if !slices.Equal(receivedProgress, tt.expectedProgress) {
+				t.Errorf("progress = %v; want %v", receivedProgress, tt.expectedProgress)
+			}
+
+			expected := "Article:\n\nSummaries of the parts of the text, in order:\n\n" + tt.expectedArticle
+			if sent[1].Content != expected {
+				t.Errorf("article sent = %q; want %q", sent[1].Content, expected)
+			}
+
+			if !slices.Equal([]Message{sent[0], sent[2], sent[3]}, []Message{messages[0], messages[2], messages[3]}) {
+				t.Errorf("sent %q; want the other messages as they are", sent)
+			}
+		})
+	}
+}
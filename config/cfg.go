@@ -1,20 +1,41 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+var (
+	errTemplate = errors.New("missing the placeholder of the template")
+	errEndpoint = errors.New("not the chat endpoint")
+)
+
 type Cfg struct {
-	HNUrlStory   string
-	HNUrlItem    string
-	HNUrlWebItem string
-	HNNumStory   int
-	OllamaURL    string
-	OllamaModel  string
-	OllamaNumCtx int
+	HNUrlStory       string
+	HNUrlItem        string
+	HNUrlWebItem     string
+	HNUrlSearch      string
+	HNUrlUpdates     string
+	HNUrlUser        string
+	HNNumStory       int
+	HNCacheDir       string
+	HNStoreDir       string
+	HNFeedTTL        time.Duration
+	HNItemTTL        time.Duration
+	HNArticleTTL     time.Duration
+	HNMaxConcurrency int
+	HNMaxRetry       int
+	HNRefresh        time.Duration
+	OllamaURL        string
+	OllamaModel      string
+	OllamaNumCtx     int
 }
 
 func LoadCfg() (*Cfg, bool) {
@@ -23,13 +44,24 @@ func LoadCfg() (*Cfg, bool) {
 	var err error
 
 	cfg := &Cfg{
-		HNUrlStory:   "",
-		HNUrlItem:    "",
-		HNUrlWebItem: "",
-		HNNumStory:   0,
-		OllamaURL:    "",
-		OllamaModel:  "",
-		OllamaNumCtx: 0,
+		HNUrlStory:       "",
+		HNUrlItem:        "",
+		HNUrlWebItem:     "",
+		HNUrlSearch:      "",
+		HNUrlUpdates:     "",
+		HNUrlUser:        "",
+		HNNumStory:       0,
+		HNCacheDir:       "",
+		HNStoreDir:       "",
+		HNFeedTTL:        0,
+		HNItemTTL:        0,
+		HNArticleTTL:     0,
+		HNMaxConcurrency: 0,
+		HNMaxRetry:       0,
+		HNRefresh:        0,
+		OllamaURL:        "",
+		OllamaModel:      "",
+		OllamaNumCtx:     0,
 	}
 
 	if err := godotenv.Load(); err != nil {
@@ -51,6 +83,21 @@ func LoadCfg() (*Cfg, bool) {
 		return nil, false
 	}
 
+	cfg.HNUrlSearch, ok = os.LookupEnv("HN_URL_SEARCH")
+	if !ok {
+		return nil, false
+	}
+
+	cfg.HNUrlUpdates, ok = os.LookupEnv("HN_URL_UPDATES")
+	if !ok {
+		return nil, false
+	}
+
+	cfg.HNUrlUser, ok = os.LookupEnv("HN_URL_USER")
+	if !ok {
+		return nil, false
+	}
+
 	hnNumStory, ok := os.LookupEnv("HN_NUM_STORY")
 	if !ok {
 		return nil, false
@@ -61,6 +108,51 @@ func LoadCfg() (*Cfg, bool) {
 		return nil, false
 	}
 
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, false
+	}
+
+	cfg.HNCacheDir = filepath.Join(cacheDir, "chamot")
+
+	cfg.HNStoreDir, ok = dataDir()
+	if !ok {
+		return nil, false
+	}
+
+	cfg.HNFeedTTL, ok = lookupDuration("HN_CACHE_TTL_FEED")
+	if !ok {
+		return nil, false
+	}
+
+	cfg.HNItemTTL, ok = lookupDuration("HN_CACHE_TTL_ITEM")
+	if !ok {
+		return nil, false
+	}
+
+	cfg.HNArticleTTL, ok = lookupDuration("HN_CACHE_TTL_ARTICLE")
+	if !ok {
+		return nil, false
+	}
+
+	cfg.HNMaxConcurrency, ok = lookupInt("HN_MAX_CONCURRENCY")
+	if !ok {
+		return nil, false
+	}
+
+	cfg.HNMaxRetry, ok = lookupInt("HN_MAX_RETRY")
+	if !ok {
+		return nil, false
+	}
+
+	// Without an interval, the stories are only refreshed on demand
+	if _, set := os.LookupEnv("HN_REFRESH_INTERVAL"); set {
+		cfg.HNRefresh, ok = lookupDuration("HN_REFRESH_INTERVAL")
+		if !ok {
+			return nil, false
+		}
+	}
+
 	cfg.OllamaURL, ok = os.LookupEnv("OLLAMA_URL")
 	if !ok {
 		return nil, false
@@ -83,3 +175,75 @@ func LoadCfg() (*Cfg, bool) {
 
 	return cfg, true
 }
+
+// Check tells if the URL templates hold the placeholder they are filled in with. HN_URL_STORY used to
+// name a single feed, e.g. .../v0/topstories.json, it now needs %s in place of the feed, e.g. .../v0/%sstories.json.
+// OLLAMA_URL used to be .../api/generate, whose answers are not those of a chat, it now needs .../api/chat.
+func (c *Cfg) Check() error {
+	templates := []struct {
+		key         string
+		value       string
+		placeholder string
+	}{
+		{"HN_URL_STORY", c.HNUrlStory, "%s"},
+		{"HN_URL_ITEM", c.HNUrlItem, "%d"},
+		{"HN_URL_WEB_ITEM", c.HNUrlWebItem, "%d"},
+		{"HN_URL_SEARCH", c.HNUrlSearch, "%s"},
+		{"HN_URL_USER", c.HNUrlUser, "%s"},
+	}
+
+	for _, template := range templates {
+		if !strings.Contains(template.value, template.placeholder) {
+			return fmt.Errorf("error in %s=%s, want %s in it: %w", template.key, template.value,
+				template.placeholder, errTemplate)
+		}
+	}
+
+	if !strings.HasSuffix(c.OllamaURL, "/api/chat") {
+		return fmt.Errorf("error in OLLAMA_URL=%s, want the /api/chat endpoint: %w", c.OllamaURL, errEndpoint)
+	}
+
+	return nil
+}
+
+func lookupInt(key string) (int, bool) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func lookupDuration(key string) (time.Duration, bool) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return 0, false
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return duration, true
+}
+
+// dataDir follows the XDG spec, as os.UserCacheDir does for the cache.
+func dataDir() (string, bool) {
+	if dir, ok := os.LookupEnv("XDG_DATA_HOME"); ok && dir != "" {
+		return filepath.Join(dir, "chamot"), true
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+
+	return filepath.Join(home, ".local", "share", "chamot"), true
+}
@@ -1,22 +1,68 @@
 package main
 
 import (
+	"chamot/cmd/browser"
 	"chamot/cmd/bubbleterm"
 	"chamot/cmd/hackernews"
+	"chamot/cmd/library"
 	"chamot/cmd/ollama"
 	"chamot/config"
+	"context"
+	"flag"
 	"log"
+	"path/filepath"
 )
 
 func main() {
+	offline := flag.Bool("offline", false, "read the stories saved by the sync command, without network")
+	flag.Parse()
+
+	ctx := context.Background()
+
 	cfg, ok := config.LoadCfg()
 	if !ok {
 		log.Fatalf("error loading config file")
 	}
 
-	hn := hackernews.NewHackerNews(cfg.HNUrlStory, cfg.HNUrlItem, cfg.HNUrlWebItem, cfg.HNNumStory)
+	if err := cfg.Check(); err != nil {
+		log.Fatalf("error checking config file: %v", err)
+	}
+
+	cache := hackernews.NewCache(cfg.HNCacheDir, cfg.HNFeedTTL, cfg.HNItemTTL, cfg.HNArticleTTL)
+	store := hackernews.NewStore(cfg.HNStoreDir)
+	hn := hackernews.NewHackerNews(cfg.HNUrlStory, cfg.HNUrlItem, cfg.HNUrlWebItem, cfg.HNUrlSearch,
+		cfg.HNUrlUpdates, cfg.HNUrlUser, cfg.HNNumStory, cache)
+	hn.SetLimits(cfg.HNMaxConcurrency, cfg.HNMaxRetry)
+
+	if flag.Arg(0) == "sync" {
+		result, err := hn.Sync(ctx, store)
+		if err != nil {
+			log.Fatalf("error syncing stories: %v", err)
+		}
+
+		for _, failure := range result.Failures {
+			log.Printf("story %d %q synced partly: %v", failure.Story.ID, failure.Story.PostTitle, failure.Err)
+		}
+
+		log.Printf("%d stories synced to %s, %d of them partly", result.Stories, cfg.HNStoreDir,
+			len(result.Failures))
+
+		return
+	}
+
+	if *offline {
+		hn = hackernews.NewOfflineHackerNews(cfg.HNUrlStory, cfg.HNUrlItem, cfg.HNUrlWebItem, cfg.HNUrlSearch,
+			cfg.HNUrlUpdates, cfg.HNUrlUser, cfg.HNNumStory, store)
+	}
+
 	ol := ollama.NewOllama(cfg.OllamaURL, cfg.OllamaModel, cfg.OllamaNumCtx)
-	bt := bubbleterm.NewBubbleTerm(hn, ol)
+
+	lib, err := library.NewLibrary(filepath.Join(cfg.HNStoreDir, "library.json"))
+	if err != nil {
+		log.Fatalf("error loading library: %v", err)
+	}
+
+	bt := bubbleterm.NewBubbleTerm(ctx, hn, ol, browser.NewBrowser(), cfg.HNRefresh, lib)
 
 	if err := bt.Run(); err != nil {
 		log.Fatalf("error running app")